@@ -0,0 +1,186 @@
+package abuse
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sweepEvery is the number of recorded misses between two sweeps of idle clients.
+const sweepEvery = 1024
+
+// Config tunes the detection of short code enumeration.
+type Config struct {
+	Window      time.Duration // Period over which misses are counted
+	BanAfter    int           // Misses within Window that get a client banned
+	BanDuration time.Duration // Time a banned client is rejected
+	DelayAfter  int           // Misses within Window after which responses to misses are delayed
+	DelayStep   time.Duration // Delay of the first delayed miss, doubled with every further miss
+	MaxDelay    time.Duration // Upper bound of the delay
+}
+
+// client is the miss history of a single client.
+type client struct {
+	windowStart time.Time // Start of the current window
+	current     int       // Misses in the current window
+	previous    int       // Misses in the previous window
+	bannedUntil time.Time // End of the ban (zero if not banned)
+	lastSeen    time.Time // Last recorded miss
+}
+
+// misses estimates the misses in the sliding window ending at now
+// by weighting the previous fixed window with its overlap.
+func (c *client) misses(now time.Time, window time.Duration) float64 {
+	elapsed := now.Sub(c.windowStart)
+	if elapsed >= 2*window {
+		return 0
+	}
+	if elapsed >= window {
+		overlap := 1 - float64(elapsed-window)/float64(window)
+		return float64(c.current) * overlap
+	}
+	overlap := 1 - float64(elapsed)/float64(window)
+	return float64(c.current) + float64(c.previous)*overlap
+}
+
+// roll moves the fixed windows forward to now.
+func (c *client) roll(now time.Time, window time.Duration) {
+	elapsed := now.Sub(c.windowStart)
+	switch {
+	case elapsed >= 2*window:
+		c.previous, c.current, c.windowStart = 0, 0, now
+	case elapsed >= window:
+		c.previous, c.current, c.windowStart = c.current, 0, c.windowStart.Add(window)
+	}
+}
+
+// Stats holds the counters of a Guard.
+type Stats struct {
+	Tracked int    `json:"tracked"` // Clients with recent misses
+	Banned  int    `json:"banned"`  // Clients currently banned
+	Bans    uint64 `json:"bans"`    // Bans since startup
+	Delayed uint64 `json:"delayed"` // Delayed responses since startup
+}
+
+// Guard detects clients enumerating short codes from the rate of lookups of unknown codes (misses).
+// Clients over DelayAfter misses get progressively slower miss responses and clients over BanAfter misses
+// are banned for BanDuration. Lookups of existing codes are never delayed and checking a client
+// is a single map lookup, so legitimate redirects are not slowed down.
+// State is kept in process memory: each instance tracks the clients it serves.
+type Guard struct {
+	config Config
+
+	mu      sync.Mutex         // Guards clients and misses
+	clients map[string]*client // Miss history by client IP address
+	misses  int                // Misses since the last sweep
+
+	bans    atomic.Uint64 // Bans since startup
+	delayed atomic.Uint64 // Delayed responses since startup
+}
+
+// NewGuard creates a new Guard with the given configuration.
+func NewGuard(config Config) *Guard {
+	return &Guard{
+		config:  config,
+		clients: make(map[string]*client),
+	}
+}
+
+// Banned reports whether ip is banned at now, and until when.
+func (g *Guard) Banned(ip string, now time.Time) (bool, time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c, ok := g.clients[ip]
+	if !ok || !now.Before(c.bannedUntil) {
+		return false, time.Time{}
+	}
+	return true, c.bannedUntil
+}
+
+// RecordMiss records a lookup of an unknown code by ip at now.
+// Returns how long to delay the response and whether the client just got banned.
+func (g *Guard) RecordMiss(ip string, path string, now time.Time) (time.Duration, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.misses++
+	if g.misses >= sweepEvery {
+		g.sweep(now)
+	}
+
+	c, ok := g.clients[ip]
+	if !ok {
+		c = &client{windowStart: now}
+		g.clients[ip] = c
+	}
+	c.roll(now, g.config.Window)
+	c.current++
+	c.lastSeen = now
+	misses := int(c.misses(now, g.config.Window))
+
+	if misses >= g.config.BanAfter && !now.Before(c.bannedUntil) {
+		c.bannedUntil = now.Add(g.config.BanDuration)
+		g.bans.Add(1)
+		slog.Warn(" [guard.go] [SECURITY EVENT] short code enumeration, client banned ",
+			slog.String("event", "enumeration_ban"),
+			slog.String("ip", ip),
+			slog.String("path", path),
+			slog.Int("misses", misses),
+			slog.Time("banned_until", c.bannedUntil),
+		)
+		return 0, true
+	}
+
+	if misses <= g.config.DelayAfter {
+		return 0, false
+	}
+	if misses == g.config.DelayAfter+1 {
+		slog.Warn(" [guard.go] [SECURITY EVENT] possible short code enumeration, delaying misses ",
+			slog.String("event", "enumeration_suspected"),
+			slog.String("ip", ip),
+			slog.String("path", path),
+			slog.Int("misses", misses),
+		)
+	}
+	g.delayed.Add(1)
+	return g.delay(misses - g.config.DelayAfter), false
+}
+
+// delay returns DelayStep doubled for every miss over DelayAfter, capped at MaxDelay.
+func (g *Guard) delay(over int) time.Duration {
+	delay := g.config.DelayStep
+	for range over - 1 {
+		if delay >= g.config.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, g.config.MaxDelay)
+}
+
+// sweep drops clients that are neither banned nor missed recently. Must be called with mu held.
+func (g *Guard) sweep(now time.Time) {
+	g.misses = 0
+	for ip, c := range g.clients {
+		if now.Sub(c.lastSeen) >= 2*g.config.Window && !now.Before(c.bannedUntil) {
+			delete(g.clients, ip)
+		}
+	}
+}
+
+// Stats returns the number of tracked and banned clients along with the ban and delay counters.
+func (g *Guard) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	stats := Stats{Tracked: len(g.clients), Bans: g.bans.Load(), Delayed: g.delayed.Load()}
+	for _, c := range g.clients {
+		if now.Before(c.bannedUntil) {
+			stats.Banned++
+		}
+	}
+	return stats
+}
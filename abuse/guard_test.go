@@ -0,0 +1,49 @@
+package abuse_test
+
+import (
+	"testing"
+	"time"
+	"urlshortener/abuse"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	guard := abuse.NewGuard(abuse.Config{
+		Window:      time.Minute,
+		BanAfter:    6,
+		BanDuration: 10 * time.Minute,
+		DelayAfter:  2,
+		DelayStep:   100 * time.Millisecond,
+		MaxDelay:    300 * time.Millisecond,
+	})
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	var delays []time.Duration
+	for i := range 5 {
+		delay, banned := guard.RecordMiss("10.0.0.1", "/abc", now.Add(time.Duration(i)*time.Second))
+		require.False(t, banned)
+		delays = append(delays, delay)
+	}
+	require.Equal(t, []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, delays)
+
+	// Other clients are not affected
+	banned, _ := guard.Banned("10.0.0.2", now)
+	require.False(t, banned)
+
+	_, banned = guard.RecordMiss("10.0.0.1", "/abc", now.Add(5*time.Second))
+	require.True(t, banned)
+	banned, until := guard.Banned("10.0.0.1", now.Add(time.Minute))
+	require.True(t, banned)
+	require.Equal(t, now.Add(5*time.Second+10*time.Minute), until)
+
+	banned, _ = guard.Banned("10.0.0.1", now.Add(11*time.Minute))
+	require.False(t, banned)
+
+	// Misses age out of the sliding window
+	delay, banned := guard.RecordMiss("10.0.0.1", "/abc", now.Add(15*time.Minute))
+	require.False(t, banned)
+	require.Zero(t, delay)
+
+	require.Equal(t, uint64(1), guard.Stats().Bans)
+}
@@ -0,0 +1,96 @@
+package autotls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Config describes how certificates are obtained from an ACME server.
+type Config struct {
+	DirectoryURL string        // ACME directory (default Let's Encrypt production)
+	Email        string        // Contact address registered with the ACME account (optional)
+	Hosts        []string      // Hosts always served besides verified domains (e.g., the default short link host)
+	RootCAs      string        // PEM file of extra CAs trusted for the ACME server, e.g., Pebble's (optional)
+	RenewBefore  time.Duration // How long before expiry certificates are renewed (default 30 days)
+}
+
+// ErrHostNotAllowed is returned by the host policy for hosts that are neither configured nor verified.
+var ErrHostNotAllowed = errors.New("autotls: host not allowed")
+
+// HostPolicy allows the configured hosts and every host for which verified returns true.
+// Hosts are compared in lower case.
+func HostPolicy(hosts []string, verified func(host string) bool) autocert.HostPolicy {
+	allowed := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		allowed[strings.ToLower(host)] = true
+	}
+	return func(ctx context.Context, host string) error {
+		host = strings.ToLower(host)
+		if allowed[host] || (verified != nil && verified(host)) {
+			return nil
+		}
+		return ErrHostNotAllowed
+	}
+}
+
+// NewManager creates an autocert.Manager obtaining and renewing certificates for the hosts allowed by
+// HostPolicy(config.Hosts, verified), stored in cache.
+func NewManager(config Config, cache autocert.Cache, verified func(host string) bool) (*autocert.Manager, error) {
+	client := &acme.Client{DirectoryURL: config.DirectoryURL}
+	if config.RootCAs != "" {
+		pem, err := os.ReadFile(config.RootCAs)
+		if err != nil {
+			return nil, err
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("autotls: no certificate found in %s", config.RootCAs)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+		client.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	return &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       cache,
+		HostPolicy:  HostPolicy(config.Hosts, verified),
+		RenewBefore: config.RenewBefore,
+		Client:      client,
+		Email:       config.Email,
+	}, nil
+}
+
+// RedirectHandler redirects plain HTTP requests to the same host and path over HTTPS on httpsPort.
+// GET and HEAD requests are redirected with 301, other methods with 308 so clients repeat them unchanged.
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		status := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+}
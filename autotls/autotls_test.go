@@ -0,0 +1,82 @@
+package autotls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestHostPolicy(t *testing.T) {
+	policy := HostPolicy([]string{"Short.Example.com"}, func(host string) bool { return host == "go.brand.com" })
+	ctx := context.Background()
+
+	require.NoError(t, policy(ctx, "short.example.com"))
+	require.NoError(t, policy(ctx, "GO.brand.com"))
+	require.ErrorIs(t, policy(ctx, "evil.example.com"), ErrHostNotAllowed)
+
+	require.ErrorIs(t, HostPolicy(nil, nil)(ctx, "short.example.com"), ErrHostNotAllowed)
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		target    string
+		httpsPort string
+		status    int
+		location  string
+	}{
+		{"default port", http.MethodGet, "http://go.brand.com/abc?x=1", "443", 301, "https://go.brand.com/abc?x=1"},
+		{"port of request dropped", http.MethodGet, "http://go.brand.com:80/abc", "", 301, "https://go.brand.com/abc"},
+		{"custom port", http.MethodHead, "http://localhost:8080/abc", "8443", 301, "https://localhost:8443/abc"},
+		{"method kept", http.MethodPost, "http://go.brand.com/shorten", "443", 308, "https://go.brand.com/shorten"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			RedirectHandler(tt.httpsPort).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+			require.Equal(t, tt.status, recorder.Code)
+			require.Equal(t, tt.location, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+// TestPebble obtains a certificate from a local Pebble ACME test server (https://github.com/letsencrypt/pebble)
+// It only runs when PEBBLE_DIRECTORY is set, e.g.:
+//
+//	PEBBLE_VA_ALWAYS_VALID=1 pebble -config test/config/pebble-config.json
+//	PEBBLE_DIRECTORY=https://localhost:14000/dir PEBBLE_CA_ROOTS=test/certs/pebble.minica.pem go test ./autotls
+func TestPebble(t *testing.T) {
+	directory := os.Getenv("PEBBLE_DIRECTORY")
+	if directory == "" {
+		t.Skip("PEBBLE_DIRECTORY not set")
+	}
+
+	manager, err := NewManager(Config{
+		DirectoryURL: directory,
+		Hosts:        []string{"short.example.com"},
+		RootCAs:      os.Getenv("PEBBLE_CA_ROOTS"),
+	}, autocert.DirCache(t.TempDir()), func(host string) bool { return host == "go.brand.com" })
+	require.NoError(t, err)
+
+	for _, host := range []string{"short.example.com", "go.brand.com"} {
+		cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		require.NoError(t, err, host)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		require.Contains(t, leaf.DNSNames, host)
+		require.True(t, leaf.NotAfter.After(time.Now()))
+	}
+
+	_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.example.com"})
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package bloom
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+)
+
+// Filter is a thread-safe Bloom filter of strings.
+// MayContain never returns false for an added item, and returns true for an item
+// that was never added with a probability close to the configured false-positive rate
+// as long as no more than the expected number of items are added.
+type Filter struct {
+	mu     sync.RWMutex // Guards bits and items
+	bits   []uint64     // Bit array
+	m      uint64       // Number of bits
+	k      uint64       // Number of hash functions
+	items  uint64       // Number of items added
+	seed   maphash.Seed // Seed of the hash function
+	fpRate float64      // Target false-positive rate
+}
+
+// New creates a Filter sized for expectedItems items at the given false-positive rate (e.g., 0.01).
+func New(expectedItems int, fpRate float64) *Filter {
+	n := float64(max(expectedItems, 1))
+	fpRate = min(max(fpRate, 1e-9), 0.5)
+
+	// Optimal number of bits and hash functions for n items at fpRate
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(max(math.Round(float64(m)/n*math.Ln2), 1))
+
+	return &Filter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		k:      k,
+		seed:   maphash.MakeSeed(),
+		fpRate: fpRate,
+	}
+}
+
+// hashes returns two independent hashes of item, combined with double hashing to derive k positions.
+func (f *Filter) hashes(item string) (uint64, uint64) {
+	h1 := maphash.String(f.seed, item)
+	h2 := h1>>33 | h1<<31 // Rotated copy, made odd so positions cycle through every bit
+	return h1, h2 | 1
+}
+
+// Add inserts item into the filter.
+func (f *Filter) Add(item string) {
+	h1, h2 := f.hashes(item)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := uint64(0); i < f.k; i++ {
+		position := (h1 + i*h2) % f.m
+		f.bits[position/64] |= 1 << (position % 64)
+	}
+	f.items++
+}
+
+// MayContain reports whether item may have been added.
+// False means item was definitely never added.
+func (f *Filter) MayContain(item string) bool {
+	h1, h2 := f.hashes(item)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for i := uint64(0); i < f.k; i++ {
+		position := (h1 + i*h2) % f.m
+		if f.bits[position/64]&(1<<(position%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats describes the size and accuracy of a Filter.
+type Stats struct {
+	Items                      uint64  `json:"items"`                         // Number of items added
+	Bits                       uint64  `json:"bits"`                          // Size of the bit array
+	Hashes                     uint64  `json:"hashes"`                        // Number of hash functions
+	TargetFalsePositiveRate    float64 `json:"target_false_positive_rate"`    // Configured false-positive rate
+	EstimatedFalsePositiveRate float64 `json:"estimated_false_positive_rate"` // False-positive rate given the items added so far
+}
+
+// Stats returns the size of the filter and its estimated false-positive rate, (1 - e^(-kn/m))^k.
+func (f *Filter) Stats() Stats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return Stats{
+		Items:                      f.items,
+		Bits:                       f.m,
+		Hashes:                     f.k,
+		TargetFalsePositiveRate:    f.fpRate,
+		EstimatedFalsePositiveRate: math.Pow(1-math.Exp(-float64(f.k*f.items)/float64(f.m)), float64(f.k)),
+	}
+}
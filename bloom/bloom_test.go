@@ -0,0 +1,29 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilter checks that added items are always found and that the observed
+// false-positive rate stays close to the configured one
+func TestFilter(t *testing.T) {
+	filter := New(10_000, 0.01)
+	for i := range 10_000 {
+		filter.Add("code-" + strconv.Itoa(i))
+	}
+	for i := range 10_000 {
+		require.True(t, filter.MayContain("code-"+strconv.Itoa(i)))
+	}
+
+	falsePositives := 0
+	for i := range 100_000 {
+		if filter.MayContain("unknown-" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	require.Less(t, float64(falsePositives)/100_000, 0.02)
+	require.InDelta(t, 0.01, filter.Stats().EstimatedFalsePositiveRate, 0.005)
+}
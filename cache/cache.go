@@ -1,15 +1,37 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss is returned by Get when the key does not exist.
+// Implementations must return it (and only it) for missing keys so callers
+// can tell a miss apart from a failing backend.
+var ErrCacheMiss = errors.New("cache miss")
 
 // Cache defines a generic interface for key-value caching systems.
+// Every method takes a context so callers can cancel or bound in-flight calls.
 type Cache interface {
-	// Get retrieves the value for a given key.
-	Get(key string) (string, error)
+	// Get retrieves the value for a given key. Returns ErrCacheMiss if the key does not exist.
+	Get(ctx context.Context, key string) (string, error)
 	// Set stores a key-value pair with an optional expiration duration.
-	Set(key string, value string, expire time.Duration)
+	Set(ctx context.Context, key string, value string, expire time.Duration) error
 	// Incr atomically increments the integer value of a key by one.
-	Incr(key string) (int64, error)
+	Incr(ctx context.Context, key string) (int64, error)
 	// Expire sets a timeout on a key. After the timeout, the key will be automatically deleted.
-	Expire(key string, expire time.Duration)
+	Expire(ctx context.Context, key string, expire time.Duration) error
+	// Del removes the given keys. Missing keys are ignored.
+	Del(ctx context.Context, keys ...string) error
+}
+
+// Locker is implemented by caches that can hold short-lived distributed locks,
+// e.g. so only one instance repopulates a cache entry after a miss.
+type Locker interface {
+	// Lock tries to acquire key for at most ttl without waiting.
+	// Returns a token to pass to Unlock and whether the lock was acquired.
+	Lock(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error)
+	// Unlock releases key if it is still held with token.
+	Unlock(ctx context.Context, key string, token string) error
 }
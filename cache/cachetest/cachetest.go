@@ -0,0 +1,126 @@
+// Package cachetest provides a conformance test suite for cache.Cache implementations.
+// Every implementation must pass it so the backends can be swapped by configuration.
+package cachetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"urlshortener/cache"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Factory creates an empty cache for a single test.
+// advance moves the clock seen by the cache forward (e.g., time.Sleep or miniredis FastForward).
+type Factory func(t *testing.T) (c cache.Cache, advance func(time.Duration))
+
+// ttl is the expiration used by the suite; short enough for sleeping clocks
+const ttl = 200 * time.Millisecond
+
+// Run runs the conformance suite against caches created by newCache.
+func Run(t *testing.T, newCache Factory) {
+	ctx := context.Background()
+
+	t.Run("GetMissing", func(t *testing.T) {
+		c, _ := newCache(t)
+		_, err := c.Get(ctx, "missing")
+		require.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("SetGet", func(t *testing.T) {
+		c, _ := newCache(t)
+		require.NoError(t, c.Set(ctx, "key", "value", 0))
+		value, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		require.Equal(t, "value", value)
+
+		require.NoError(t, c.Set(ctx, "key", "other", 0))
+		value, err = c.Get(ctx, "key")
+		require.NoError(t, err)
+		require.Equal(t, "other", value)
+	})
+
+	t.Run("SetTTL", func(t *testing.T) {
+		c, advance := newCache(t)
+		require.NoError(t, c.Set(ctx, "key", "value", ttl))
+		require.NoError(t, c.Set(ctx, "forever", "value", 0))
+
+		advance(2 * ttl)
+		_, err := c.Get(ctx, "key")
+		require.ErrorIs(t, err, cache.ErrCacheMiss)
+		_, err = c.Get(ctx, "forever")
+		require.NoError(t, err)
+	})
+
+	t.Run("Incr", func(t *testing.T) {
+		c, _ := newCache(t)
+		for want := int64(1); want <= 3; want++ {
+			count, err := c.Incr(ctx, "counter")
+			require.NoError(t, err)
+			require.Equal(t, want, count)
+		}
+
+		require.NoError(t, c.Set(ctx, "text", "abc", 0))
+		_, err := c.Incr(ctx, "text")
+		require.Error(t, err)
+	})
+
+	t.Run("IncrKeepsTTL", func(t *testing.T) {
+		c, advance := newCache(t)
+		require.NoError(t, c.Set(ctx, "counter", "5", ttl))
+		count, err := c.Incr(ctx, "counter")
+		require.NoError(t, err)
+		require.Equal(t, int64(6), count)
+
+		advance(2 * ttl)
+		_, err = c.Get(ctx, "counter")
+		require.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("IncrConcurrent", func(t *testing.T) {
+		c, _ := newCache(t)
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					_, err := c.Incr(ctx, "counter")
+					require.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		value, err := c.Get(ctx, "counter")
+		require.NoError(t, err)
+		require.Equal(t, "1000", value)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		c, advance := newCache(t)
+		require.NoError(t, c.Set(ctx, "key", "value", 0))
+		require.NoError(t, c.Expire(ctx, "key", ttl))
+		require.NoError(t, c.Expire(ctx, "missing", ttl))
+
+		_, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		advance(2 * ttl)
+		_, err = c.Get(ctx, "key")
+		require.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("Del", func(t *testing.T) {
+		c, _ := newCache(t)
+		require.NoError(t, c.Set(ctx, "a", "1", 0))
+		require.NoError(t, c.Set(ctx, "b", "2", 0))
+		require.NoError(t, c.Del(ctx, "a", "b", "missing"))
+
+		for _, key := range []string{"a", "b"} {
+			_, err := c.Get(ctx, key)
+			require.ErrorIs(t, err, cache.ErrCacheMiss)
+		}
+	})
+}
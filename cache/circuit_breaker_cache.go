@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+	"urlshortener/utils"
+)
+
+// BreakerState is the state of a CircuitBreakerCache.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Backend is healthy, calls pass through
+	BreakerOpen     BreakerState = "open"      // Backend is failing, calls are rejected immediately
+	BreakerHalfOpen BreakerState = "half_open" // Cooldown elapsed, a single probe call is let through
+)
+
+// CircuitBreakerCache wraps a Cache and stops calling it after repeated failures.
+// While the breaker is open every call fails fast with utils.ErrCacheUnavailable, so callers
+// can bypass the cache (e.g., go straight to MySQL) instead of waiting on a dead backend.
+// After the cooldown a single probe call is let through; if it succeeds the breaker closes again,
+// which gives lazy reconnection to the backend without any background work.
+// Cache misses and cancelled requests are not counted as failures.
+// Keys whose deletion didn't reach the backend are remembered and deleted before any other call once
+// the backend answers again, so invalidations made while it was down don't leave stale entries behind.
+type CircuitBreakerCache struct {
+	cache            Cache         // Wrapped cache (e.g., RedisCache)
+	failureThreshold int           // Consecutive failures that open the breaker
+	cooldown         time.Duration // Time the breaker stays open before probing the backend
+
+	mu       sync.Mutex   // Guards the fields below
+	state    BreakerState // Current breaker state
+	failures int          // Consecutive failures while closed
+	openedAt time.Time    // When the breaker last opened
+	probing  bool         // Whether a half-open probe is in flight
+
+	pending map[string]uint64 // Keys whose deletion failed, with the sequence number of the last failure
+	seq     uint64            // Sequence number of the last failed deletion
+}
+
+// maxPendingDels bounds the number of keys remembered while deletions can't reach the backend
+const maxPendingDels = 100000
+
+// NewCircuitBreakerCache wraps cache with a circuit breaker that opens after failureThreshold
+// consecutive failures and probes the backend again after cooldown.
+func NewCircuitBreakerCache(cache Cache, failureThreshold int, cooldown time.Duration) *CircuitBreakerCache {
+	return &CircuitBreakerCache{
+		cache:            cache,
+		failureThreshold: max(failureThreshold, 1),
+		cooldown:         cooldown,
+		state:            BreakerClosed,
+		pending:          make(map[string]uint64),
+	}
+}
+
+// State returns the current state of the breaker.
+func (c *CircuitBreakerCache) State() BreakerState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == BreakerOpen && time.Since(c.openedAt) >= c.cooldown {
+		return BreakerHalfOpen // Next call will probe the backend
+	}
+	return c.state
+}
+
+// Healthy reports whether the wrapped cache is currently usable.
+func (c *CircuitBreakerCache) Healthy() bool {
+	return c.State() == BreakerClosed
+}
+
+// allow reports whether a call may be sent to the wrapped cache.
+func (c *CircuitBreakerCache) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case BreakerOpen:
+		if time.Since(c.openedAt) < c.cooldown {
+			return false
+		}
+		c.state = BreakerHalfOpen
+		c.probing = true
+		return true
+	case BreakerHalfOpen:
+		if c.probing {
+			return false // Only one probe at a time
+		}
+		c.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call to the wrapped cache.
+func (c *CircuitBreakerCache) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Cancellations come from the client, not the backend; let the next call probe again
+	if errors.Is(err, context.Canceled) {
+		c.probing = false
+		return
+	}
+
+	// Misses are healthy answers from the backend
+	if err == nil || errors.Is(err, ErrCacheMiss) {
+		if c.state != BreakerClosed {
+			slog.Info(" [circuit_breaker_cache.go] [BREAKER CLOSED] ")
+		}
+		c.state = BreakerClosed
+		c.failures = 0
+		c.probing = false
+		return
+	}
+
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= c.failureThreshold {
+		if c.state != BreakerOpen {
+			slog.Warn(" [circuit_breaker_cache.go] [BREAKER OPEN] ", slog.Any("error", err))
+		}
+		c.state = BreakerOpen
+		c.openedAt = time.Now()
+		c.probing = false
+	}
+}
+
+// Get retrieves the value for a given key, failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Get(ctx context.Context, key string) (string, error) {
+	if err := c.begin(ctx); err != nil {
+		return "", err
+	}
+	value, err := c.cache.Get(ctx, key)
+	c.record(err)
+	return value, err
+}
+
+// Set stores a key-value pair, failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Set(ctx context.Context, key string, value string, expire time.Duration) error {
+	if err := c.begin(ctx); err != nil {
+		return err
+	}
+	err := c.cache.Set(ctx, key, value, expire)
+	c.record(err)
+	return err
+}
+
+// Incr atomically increments a key, failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Incr(ctx context.Context, key string) (int64, error) {
+	if err := c.begin(ctx); err != nil {
+		return 0, err
+	}
+	count, err := c.cache.Incr(ctx, key)
+	c.record(err)
+	return count, err
+}
+
+// Expire sets a timeout on a key, failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Expire(ctx context.Context, key string, expire time.Duration) error {
+	if err := c.begin(ctx); err != nil {
+		return err
+	}
+	err := c.cache.Expire(ctx, key, expire)
+	c.record(err)
+	return err
+}
+
+// Del removes the given keys, failing fast with utils.ErrCacheUnavailable while the breaker is open.
+// Keys that couldn't be deleted are deleted again once the backend answers.
+func (c *CircuitBreakerCache) Del(ctx context.Context, keys ...string) error {
+	if err := c.begin(ctx); err != nil {
+		c.remember(keys)
+		return err
+	}
+	err := c.cache.Del(ctx, keys...)
+	c.record(err)
+	if err != nil {
+		c.remember(keys)
+	}
+	return err
+}
+
+// remember queues keys whose deletion didn't reach the backend.
+func (c *CircuitBreakerCache) remember(keys []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := c.pending[key]; !ok && len(c.pending) >= maxPendingDels {
+			slog.Warn(" [circuit_breaker_cache.go] [PENDING DELS FULL] ", slog.String("key", key))
+			continue
+		}
+		c.seq++
+		c.pending[key] = c.seq
+	}
+}
+
+// begin reports whether a call may be sent to the wrapped cache, returning utils.ErrCacheUnavailable if not.
+// Pending deletions are replayed first; if they fail the call is not made, as it could return a stale entry.
+func (c *CircuitBreakerCache) begin(ctx context.Context) error {
+	if !c.allow() {
+		return utils.ErrCacheUnavailable
+	}
+
+	c.mu.Lock()
+	if len(c.pending) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	keys := make([]string, 0, len(c.pending))
+	seqs := make([]uint64, 0, len(c.pending))
+	for key, seq := range c.pending {
+		keys = append(keys, key)
+		seqs = append(seqs, seq)
+	}
+	c.mu.Unlock()
+
+	if err := c.cache.Del(ctx, keys...); err != nil {
+		c.record(err)
+		return utils.ErrCacheUnavailable
+	}
+	c.record(nil)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, key := range keys {
+		if c.pending[key] == seqs[i] { // Keep keys whose deletion failed again meanwhile
+			delete(c.pending, key)
+		}
+	}
+	slog.Info(" [circuit_breaker_cache.go] [PENDING DELS REPLAYED] ", slog.Int("keys", len(keys)))
+	return nil
+}
+
+// Lock acquires a lock on the wrapped cache if it implements Locker,
+// failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	locker, ok := c.cache.(Locker)
+	if !ok {
+		return "", false, utils.ErrCacheUnavailable
+	}
+	if err := c.begin(ctx); err != nil {
+		return "", false, err
+	}
+	token, acquired, err := locker.Lock(ctx, key, ttl)
+	c.record(err)
+	return token, acquired, err
+}
+
+// Unlock releases a lock on the wrapped cache if it implements Locker,
+// failing fast with utils.ErrCacheUnavailable while the breaker is open.
+func (c *CircuitBreakerCache) Unlock(ctx context.Context, key string, token string) error {
+	locker, ok := c.cache.(Locker)
+	if !ok {
+		return utils.ErrCacheUnavailable
+	}
+	if err := c.begin(ctx); err != nil {
+		return err
+	}
+	err := locker.Unlock(ctx, key, token)
+	c.record(err)
+	return err
+}
+
+// Do runs call against the backend of the wrapped cache (e.g., a Lua script on the same Redis),
+// failing fast with utils.ErrCacheUnavailable while the breaker is open.
+// Its outcome counts towards opening and closing the breaker like any cache call.
+func (c *CircuitBreakerCache) Do(ctx context.Context, call func(ctx context.Context) error) error {
+	if err := c.begin(ctx); err != nil {
+		return err
+	}
+	err := call(ctx)
+	c.record(err)
+	return err
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flakyCache is a Cache whose Get fails while down is set
+type flakyCache struct {
+	Cache
+	down  bool
+	calls int
+}
+
+func (f *flakyCache) Get(ctx context.Context, key string) (string, error) {
+	f.calls++
+	if f.down {
+		return "", errors.New("connection refused")
+	}
+	return "", ErrCacheMiss
+}
+
+// TestCircuitBreakerCache checks that the breaker opens after repeated failures,
+// fails fast while open and closes again once a probe succeeds
+func TestCircuitBreakerCache(t *testing.T) {
+	ctx := context.Background()
+	backend := &flakyCache{down: true}
+	breaker := NewCircuitBreakerCache(backend, 3, 50*time.Millisecond)
+
+	for range 3 {
+		_, err := breaker.Get(ctx, "key")
+		require.NotErrorIs(t, err, utils.ErrCacheUnavailable)
+	}
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// Open: calls fail fast without reaching the backend
+	_, err := breaker.Get(ctx, "key")
+	require.ErrorIs(t, err, utils.ErrCacheUnavailable)
+	require.Equal(t, 3, backend.calls)
+
+	// Half-open probe fails: breaker opens again
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, BreakerHalfOpen, breaker.State())
+	_, err = breaker.Get(ctx, "key")
+	require.NotErrorIs(t, err, utils.ErrCacheUnavailable)
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// Half-open probe succeeds (a miss is a healthy answer): breaker closes
+	backend.down = false
+	time.Sleep(60 * time.Millisecond)
+	_, err = breaker.Get(ctx, "key")
+	require.ErrorIs(t, err, ErrCacheMiss)
+	require.True(t, breaker.Healthy())
+}
+
+// downCache is a MemoryCache whose calls fail while down is set
+type downCache struct {
+	*MemoryCache
+	down bool
+}
+
+func (d *downCache) Get(ctx context.Context, key string) (string, error) {
+	if d.down {
+		return "", errors.New("connection refused")
+	}
+	return d.MemoryCache.Get(ctx, key)
+}
+
+func (d *downCache) Del(ctx context.Context, keys ...string) error {
+	if d.down {
+		return errors.New("connection refused")
+	}
+	return d.MemoryCache.Del(ctx, keys...)
+}
+
+// TestCircuitBreakerCacheReplaysDeletes checks that keys whose deletion failed while the backend was down
+// are deleted before the backend serves them again
+func TestCircuitBreakerCacheReplaysDeletes(t *testing.T) {
+	ctx := context.Background()
+	backend := &downCache{MemoryCache: NewMemoryCache(1 << 20)}
+	breaker := NewCircuitBreakerCache(backend, 1, 50*time.Millisecond)
+	require.NoError(t, breaker.Set(ctx, "short:abc", "old destination", time.Hour))
+
+	// The first deletion fails on the backend and opens the breaker, the second is rejected by it
+	backend.down = true
+	require.Error(t, breaker.Del(ctx, "short:abc"))
+	require.ErrorIs(t, breaker.Del(ctx, "expire:abc"), utils.ErrCacheUnavailable)
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// Recovered: the probe deletes the pending keys before reading, so the old entry is never served
+	backend.down = false
+	time.Sleep(60 * time.Millisecond)
+	_, err := breaker.Get(ctx, "short:abc")
+	require.ErrorIs(t, err, ErrCacheMiss)
+	require.True(t, breaker.Healthy())
+	require.Empty(t, breaker.pending)
+}
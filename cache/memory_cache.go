@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNotInteger is returned by MemoryCache.Incr when the stored value is not an integer.
+var ErrNotInteger = errors.New("value is not an integer")
+
+// entryOverhead approximates the bytes used by a cache entry besides its key and value
+// (list element, map bucket slot and entry struct).
+const entryOverhead = 64
+
+// memoryEntry is a single key-value pair stored by MemoryCache.
+type memoryEntry struct {
+	key      string    // Key of the entry
+	value    string    // Stored value
+	expireAt time.Time // When the entry expires (zero means never)
+}
+
+// size returns the approximate memory used by the entry in bytes.
+func (e *memoryEntry) size() int {
+	return len(e.key) + len(e.value) + entryOverhead
+}
+
+// expired reports whether the entry has expired at the given time.
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// MemoryCache implements the Cache interface in process memory.
+// Entries are evicted in least-recently-used order once the memory budget is exceeded,
+// and expired entries are dropped lazily when they are accessed or evicted.
+// It is safe for concurrent use, which makes it suitable for local development
+// and small single-instance deployments without Redis.
+type MemoryCache struct {
+	mu       sync.Mutex               // Guards every field below
+	entries  map[string]*list.Element // Key to element in lru
+	lru      *list.List               // Entries from most to least recently used
+	used     int                      // Approximate bytes used by all entries
+	maxBytes int                      // Memory budget in bytes
+}
+
+// NewMemoryCache creates a new MemoryCache that keeps at most maxBytes of keys and values.
+func NewMemoryCache(maxBytes int) *MemoryCache {
+	return &MemoryCache{
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		maxBytes: maxBytes,
+	}
+}
+
+// lookup returns the live entry for key, removing it if it has expired.
+// Must be called with mu held.
+func (m *MemoryCache) lookup(key string, now time.Time) *list.Element {
+	element, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+	if element.Value.(*memoryEntry).expired(now) {
+		m.remove(element)
+		return nil
+	}
+	return element
+}
+
+// remove drops an element from the cache. Must be called with mu held.
+func (m *MemoryCache) remove(element *list.Element) {
+	entry := m.lru.Remove(element).(*memoryEntry)
+	delete(m.entries, entry.key)
+	m.used -= entry.size()
+}
+
+// store inserts or replaces the entry for key and evicts least recently used entries
+// until the cache fits its memory budget. Must be called with mu held.
+func (m *MemoryCache) store(key string, value string, expireAt time.Time) {
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+
+	entry := &memoryEntry{key: key, value: value, expireAt: expireAt}
+	if entry.size() > m.maxBytes {
+		return // Entry alone exceeds the budget, don't evict everything for it
+	}
+
+	m.entries[key] = m.lru.PushFront(entry)
+	m.used += entry.size()
+
+	for m.used > m.maxBytes {
+		m.remove(m.lru.Back())
+	}
+}
+
+// Get retrieves the value for a given key and marks it as recently used.
+// Returns ErrCacheMiss if the key does not exist or has expired.
+func (m *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element := m.lookup(key, time.Now())
+	if element == nil {
+		return "", ErrCacheMiss
+	}
+	m.lru.MoveToFront(element)
+	return element.Value.(*memoryEntry).value, nil
+}
+
+// Set stores a key-value pair with the specified expiration duration.
+// If expire is 0, the key does not expire.
+func (m *MemoryCache) Set(ctx context.Context, key string, value string, expire time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expireAt time.Time
+	if expire > 0 {
+		expireAt = time.Now().Add(expire)
+	}
+	m.store(key, value, expireAt)
+	return nil
+}
+
+// Incr atomically increments the integer value of a key by one, keeping its expiration.
+// A missing key is treated as 0. Returns ErrNotInteger if the value is not an integer.
+func (m *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	var expireAt time.Time
+	if element := m.lookup(key, time.Now()); element != nil {
+		entry := element.Value.(*memoryEntry)
+		value, err := strconv.ParseInt(entry.value, 10, 64)
+		if err != nil {
+			return 0, ErrNotInteger
+		}
+		count, expireAt = value, entry.expireAt
+	}
+
+	count++
+	m.store(key, strconv.FormatInt(count, 10), expireAt)
+	return count, nil
+}
+
+// Expire sets a timeout on a key. After the timeout, the key will be automatically deleted.
+// Missing keys are ignored.
+func (m *MemoryCache) Expire(ctx context.Context, key string, expire time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if element := m.lookup(key, now); element != nil {
+		element.Value.(*memoryEntry).expireAt = now.Add(expire)
+	}
+	return nil
+}
+
+// Del removes the given keys. Missing keys are ignored.
+func (m *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := m.entries[key]; ok {
+			m.remove(element)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries currently stored, including expired entries not yet dropped.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/cache/cachetest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCacheConformance(t *testing.T) {
+	cachetest.Run(t, func(t *testing.T) (cache.Cache, func(time.Duration)) {
+		return cache.NewMemoryCache(1 << 20), time.Sleep
+	})
+}
+
+// TestMemoryCacheEviction checks that least recently used entries are evicted to stay within the memory budget
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(10 * (64 + 10)) // Room for 10 entries with 5-byte keys and values
+
+	for i := range 10 {
+		require.NoError(t, c.Set(ctx, fmt.Sprintf("key-%d", i), "value", 0))
+	}
+	_, err := c.Get(ctx, "key-0") // key-0 becomes most recently used
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, "key-a", "value", 0))
+	require.Equal(t, 10, c.Len())
+
+	_, err = c.Get(ctx, "key-1") // key-1 was least recently used
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+	_, err = c.Get(ctx, "key-0")
+	require.NoError(t, err)
+
+	// Values larger than the whole budget are not stored
+	require.NoError(t, c.Set(ctx, "huge", string(make([]byte, 1000)), 0))
+	_, err = c.Get(ctx, "huge")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+	require.Equal(t, 10, c.Len())
+}
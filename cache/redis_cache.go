@@ -4,44 +4,99 @@ import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisCache implements the Cache interface using a Redis backend.
 // It provides methods to get and set key-value pairs in Redis with optional expiration.
+// Every call is bounded by a per-call timeout so a slow Redis cannot hang requests.
 type RedisCache struct {
-	redis *redis.Client   // Redis client instance
-	ctx   context.Context // Context for Redis operations
+	redis   *redis.Client // Redis client instance
+	timeout time.Duration // Maximum duration of a single Redis call (0 means no extra timeout)
 }
 
-// NewRedisCache creates a new RedisCache with the given Redis client.
-func NewRedisCache(redis *redis.Client) *RedisCache {
+// NewRedisCache creates a new RedisCache with the given Redis client and per-call timeout.
+func NewRedisCache(redis *redis.Client, timeout time.Duration) *RedisCache {
 	return &RedisCache{
-		redis: redis,
-		ctx:   context.Background(),
+		redis:   redis,
+		timeout: timeout,
 	}
 }
 
+// withTimeout derives a context bounded by the per-call timeout.
+func (r *RedisCache) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.timeout)
+}
+
 // Get retrieves the value for a given key from Redis.
-// Returns the value as a string or an error if the key does not exist or on failure.
-func (r *RedisCache) Get(key string) (string, error) {
-	return r.redis.Get(r.ctx, key).Result()
+// Returns the value as a string, ErrCacheMiss if the key does not exist, or an error on failure.
+func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	value, err := r.redis.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrCacheMiss
+	}
+	return value, err
 }
 
 // Set stores a key-value pair in Redis with the specified expiration duration.
 // If expire is 0, the key does not expire.
-func (r *RedisCache) Set(key string, value string, expire time.Duration) {
-	r.redis.Set(r.ctx, key, value, expire)
+func (r *RedisCache) Set(ctx context.Context, key string, value string, expire time.Duration) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return r.redis.Set(ctx, key, value, expire).Err()
 }
 
 // Incr atomically increments the integer value of a key by one in Redis.
 // Returns the new value as int64 or an error if the operation fails.
-func (r *RedisCache) Incr(key string) (int64, error) {
-	return r.redis.Incr(r.ctx, key).Result()
+func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return r.redis.Incr(ctx, key).Result()
 }
 
 // Expire sets a timeout on a key in Redis. After the timeout, the key will be automatically deleted.
 // If expire is 0, the key will not expire.
-func (r *RedisCache) Expire(key string, expire time.Duration) {
-	r.redis.Expire(r.ctx, key, expire).Result()
+func (r *RedisCache) Expire(ctx context.Context, key string, expire time.Duration) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return r.redis.PExpire(ctx, key, expire).Err() // Millisecond precision
+}
+
+// Del removes the given keys from Redis. Missing keys are ignored.
+func (r *RedisCache) Del(ctx context.Context, keys ...string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return r.redis.Del(ctx, keys...).Err()
+}
+
+// unlockScript deletes a lock only if it still holds the caller's token,
+// so a lock that expired and was taken by someone else is never released by mistake.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock tries to acquire key for at most ttl with SET NX PX.
+// Returns a random token to pass to Unlock and whether the lock was acquired.
+func (r *RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	token := uuid.New().String()
+	ok, err := r.redis.SetNX(ctx, key, token, ttl).Result()
+	return token, ok, err
+}
+
+// Unlock releases key if it is still held with token.
+func (r *RedisCache) Unlock(ctx context.Context, key string, token string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	return unlockScript.Run(ctx, r.redis, []string{key}, token).Err()
 }
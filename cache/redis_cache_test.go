@@ -0,0 +1,20 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/cache/cachetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisCacheConformance(t *testing.T) {
+	cachetest.Run(t, func(t *testing.T) (cache.Cache, func(time.Duration)) {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return cache.NewRedisCache(client, time.Second), server.FastForward
+	})
+}
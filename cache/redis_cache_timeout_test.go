@@ -0,0 +1,61 @@
+package cache_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+	"urlshortener/cache"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// silentRedis accepts connections and never answers, like a Redis that stopped responding
+func silentRedis(t *testing.T) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	// Same option as redis.NewRedisClient, without which deadlines only apply after the 3s ReadTimeout
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), ContextTimeoutEnabled: true})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestRedisCacheTimeout checks that calls to a Redis that stopped answering give up after the per-call timeout
+func TestRedisCacheTimeout(t *testing.T) {
+	redisCache := cache.NewRedisCache(silentRedis(t), 50*time.Millisecond)
+
+	start := time.Now()
+	_, err := redisCache.Get(context.Background(), "key")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+
+	start = time.Now()
+	require.ErrorIs(t, redisCache.Set(context.Background(), "key", "value", time.Minute), context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+// TestRedisCacheCancel checks that calls made with a cancelled context don't wait for Redis
+// go-redis only applies deadlines to pending reads, so calls in flight are bounded by the per-call timeout instead
+func TestRedisCacheCancel(t *testing.T) {
+	redisCache := cache.NewRedisCache(silentRedis(t), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	_, err := redisCache.Get(ctx, "key")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, redisCache.Del(ctx, "key"), context.Canceled)
+	require.Less(t, time.Since(start), time.Second)
+}
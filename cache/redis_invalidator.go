@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisInvalidator implements the Invalidator interface with Redis pub/sub.
+// Messages published while an instance is disconnected are lost; TieredCache bounds
+// the resulting staleness with its local TTL.
+type RedisInvalidator struct {
+	redis   *redis.Client // Redis client instance
+	channel string        // Pub/sub channel carrying invalidated keys
+}
+
+// NewRedisInvalidator creates a new RedisInvalidator publishing on the given channel.
+func NewRedisInvalidator(redis *redis.Client, channel string) *RedisInvalidator {
+	return &RedisInvalidator{
+		redis:   redis,
+		channel: channel,
+	}
+}
+
+// Publish announces that key changed to every subscribed instance.
+func (r *RedisInvalidator) Publish(ctx context.Context, key string) error {
+	return r.redis.Publish(ctx, r.channel, key).Err()
+}
+
+// Subscribe calls onInvalidate for every published key until ctx is cancelled.
+// The subscription is re-established automatically if the connection drops.
+func (r *RedisInvalidator) Subscribe(ctx context.Context, onInvalidate func(key string)) {
+	pubsub := r.redis.Subscribe(ctx, r.channel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			onInvalidate(message.Payload)
+		}
+	}
+}
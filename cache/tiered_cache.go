@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Invalidator broadcasts key invalidations to every process sharing a remote cache.
+type Invalidator interface {
+	// Publish announces that key changed and must be dropped from local caches.
+	Publish(ctx context.Context, key string) error
+	// Subscribe calls onInvalidate for every published key until ctx is cancelled.
+	Subscribe(ctx context.Context, onInvalidate func(key string))
+}
+
+// TieredCacheStats holds hit and miss counters for each tier of a TieredCache.
+type TieredCacheStats struct {
+	LocalHits    uint64 `json:"local_hits"`    // Lookups answered by the local tier
+	LocalMisses  uint64 `json:"local_misses"`  // Lookups that fell through to the remote tier
+	RemoteHits   uint64 `json:"remote_hits"`   // Remote lookups that found the key
+	RemoteMisses uint64 `json:"remote_misses"` // Remote lookups that did not find the key
+	RemoteErrors uint64 `json:"remote_errors"` // Remote lookups that failed
+}
+
+// TieredCache implements the Cache interface with a small in-process cache in front of a remote cache.
+// Only keys with one of the configured prefixes (e.g., "short:") are kept locally, so a viral link
+// is served from process memory instead of sending every request to the same Redis key.
+// Local entries live at most localTTL, which bounds staleness even if an invalidation is lost;
+// deletes are broadcast through the Invalidator so other instances drop them right away.
+type TieredCache struct {
+	local    *MemoryCache  // Per-process hot-key tier
+	remote   Cache         // Shared tier (e.g., Redis behind a circuit breaker)
+	bus      Invalidator   // Broadcasts invalidations to other instances
+	localTTL time.Duration // Maximum lifetime of a local entry
+	prefixes []string      // Key prefixes kept in the local tier
+
+	localHits    atomic.Uint64 // Lookups answered by the local tier
+	localMisses  atomic.Uint64 // Lookups that fell through to the remote tier
+	remoteHits   atomic.Uint64 // Remote lookups that found the key
+	remoteMisses atomic.Uint64 // Remote lookups that did not find the key
+	remoteErrors atomic.Uint64 // Remote lookups that failed
+}
+
+// NewTieredCache creates a TieredCache keeping keys with the given prefixes in local for at most localTTL
+// in front of remote. Invalidations are published and received through bus.
+func NewTieredCache(local *MemoryCache, remote Cache, bus Invalidator, localTTL time.Duration, prefixes ...string) *TieredCache {
+	return &TieredCache{
+		local:    local,
+		remote:   remote,
+		bus:      bus,
+		localTTL: localTTL,
+		prefixes: prefixes,
+	}
+}
+
+// Listen drops local entries invalidated by other instances until ctx is cancelled.
+// It blocks, so it is usually started in its own goroutine.
+func (t *TieredCache) Listen(ctx context.Context) {
+	t.bus.Subscribe(ctx, func(key string) {
+		t.local.Del(ctx, key)
+	})
+}
+
+// Stats returns a snapshot of the hit and miss counters of both tiers.
+func (t *TieredCache) Stats() TieredCacheStats {
+	return TieredCacheStats{
+		LocalHits:    t.localHits.Load(),
+		LocalMisses:  t.localMisses.Load(),
+		RemoteHits:   t.remoteHits.Load(),
+		RemoteMisses: t.remoteMisses.Load(),
+		RemoteErrors: t.remoteErrors.Load(),
+	}
+}
+
+// tiered reports whether key is kept in the local tier.
+func (t *TieredCache) tiered(key string) bool {
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get retrieves the value for a given key from the local tier, falling back to the remote tier.
+// Values found remotely are kept locally for at most localTTL.
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if t.tiered(key) {
+		if value, err := t.local.Get(ctx, key); err == nil {
+			t.localHits.Add(1)
+			return value, nil
+		}
+		t.localMisses.Add(1)
+	}
+
+	value, err := t.remote.Get(ctx, key)
+	switch {
+	case err == nil:
+		t.remoteHits.Add(1)
+		if t.tiered(key) {
+			t.local.Set(ctx, key, value, t.localTTL)
+		}
+	case errors.Is(err, ErrCacheMiss):
+		t.remoteMisses.Add(1)
+	default:
+		t.remoteErrors.Add(1)
+	}
+	return value, err
+}
+
+// Set stores a key-value pair in the remote tier and, for tiered keys, in the local tier.
+// The local copy never outlives expire or localTTL, whichever is shorter.
+func (t *TieredCache) Set(ctx context.Context, key string, value string, expire time.Duration) error {
+	if t.tiered(key) {
+		localExpire := t.localTTL
+		if expire > 0 {
+			localExpire = min(expire, t.localTTL)
+		}
+		t.local.Set(ctx, key, value, localExpire)
+	}
+	return t.remote.Set(ctx, key, value, expire)
+}
+
+// Incr atomically increments the integer value of a key in the remote tier.
+// Counters are never kept locally since every instance must see the same value.
+func (t *TieredCache) Incr(ctx context.Context, key string) (int64, error) {
+	return t.remote.Incr(ctx, key)
+}
+
+// Expire sets a timeout on a key in the remote tier and drops the local copy.
+func (t *TieredCache) Expire(ctx context.Context, key string, expire time.Duration) error {
+	if t.tiered(key) {
+		t.local.Del(ctx, key)
+	}
+	return t.remote.Expire(ctx, key, expire)
+}
+
+// Del removes the given keys from both tiers and tells other instances to drop their local copies.
+func (t *TieredCache) Del(ctx context.Context, keys ...string) error {
+	t.local.Del(ctx, keys...)
+	err := t.remote.Del(ctx, keys...)
+
+	for _, key := range keys {
+		if !t.tiered(key) {
+			continue
+		}
+		if pubErr := t.bus.Publish(ctx, key); pubErr != nil {
+			// Other instances will serve the stale value for at most localTTL
+			slog.Warn(" [tiered_cache.go] [PUBLISH INVALIDATION] ", slog.String("key", key), slog.Any("error", pubErr))
+		}
+	}
+	return err
+}
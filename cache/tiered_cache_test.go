@@ -0,0 +1,71 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/cache/cachetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// newTieredCache creates a TieredCache for "short:" keys in front of the given Redis server
+func newTieredCache(t *testing.T, server *miniredis.Miniredis) *cache.TieredCache {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	tiered := cache.NewTieredCache(
+		cache.NewMemoryCache(1<<20),
+		cache.NewRedisCache(client, time.Second),
+		cache.NewRedisInvalidator(client, "invalidate"),
+		time.Minute,
+		"short:",
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go tiered.Listen(ctx)
+	return tiered
+}
+
+func TestTieredCacheConformance(t *testing.T) {
+	cachetest.Run(t, func(t *testing.T) (cache.Cache, func(time.Duration)) {
+		server := miniredis.RunT(t)
+		return newTieredCache(t, server), server.FastForward
+	})
+}
+
+// TestTieredCacheInvalidation checks that hot keys are served locally and that
+// a delete on one instance drops the local copy on every other instance
+func TestTieredCacheInvalidation(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	first, second := newTieredCache(t, server), newTieredCache(t, server)
+	time.Sleep(50 * time.Millisecond) // Let both subscriptions register
+
+	require.NoError(t, first.Set(ctx, "short:abc", "v1", 0))
+
+	// Second instance misses locally once, then serves the key from its local tier
+	for range 3 {
+		value, err := second.Get(ctx, "short:abc")
+		require.NoError(t, err)
+		require.Equal(t, "v1", value)
+	}
+	require.Equal(t, cache.TieredCacheStats{LocalHits: 2, LocalMisses: 1, RemoteHits: 1}, second.Stats())
+
+	// Changing Redis directly is invisible to the local tier...
+	server.Set("short:abc", "v2")
+	value, err := second.Get(ctx, "short:abc")
+	require.NoError(t, err)
+	require.Equal(t, "v1", value)
+
+	// ...until another instance deletes the key
+	require.NoError(t, first.Del(ctx, "short:abc"))
+	require.Eventually(t, func() bool {
+		_, err := second.Get(ctx, "short:abc")
+		return err == cache.ErrCacheMiss
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/limiter"
+	"urlshortener/quota"
+	Redis "urlshortener/redis"
+	"urlshortener/utils"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheBackend is the cache selected by configuration along with the resources behind it
+type cacheBackend struct {
+	cache   cache.Cache                // Cache used by repositories and middleware
+	breaker *cache.CircuitBreakerCache // Breaker guarding Redis (nil for the memory backend)
+	redis   *redis.Client              // Redis client for pub/sub (nil for the memory backend)
+	close   func()                     // Releases the backend's resources
+}
+
+// newInvalidator returns an Invalidator broadcasting on channel to every instance,
+// or nil for the memory backend, which only serves a single instance
+func (b cacheBackend) newInvalidator(channel string) cache.Invalidator {
+	if b.redis == nil {
+		return nil
+	}
+	return cache.NewRedisInvalidator(b.redis, channel)
+}
+
+// newLimiter returns a Limiter sharing its token buckets with every instance through Redis,
+// or keeping them in process memory for the memory backend
+func (b cacheBackend) newLimiter() limiter.Limiter {
+	if b.redis == nil {
+		return limiter.NewMemoryLimiter()
+	}
+	return limiter.NewRedisLimiter(b.redis, utils.GetEnvDuration("REDIS_TIMEOUT", 200*time.Millisecond), b.breaker)
+}
+
+// newQuotaCounter returns a quota Counter shared by every instance through Redis,
+// or kept in process memory for the memory backend
+func (b cacheBackend) newQuotaCounter() quota.Counter {
+	if b.redis == nil {
+		return quota.NewMemoryCounter()
+	}
+	return quota.NewRedisCounter(b.redis, utils.GetEnvDuration("REDIS_TIMEOUT", 200*time.Millisecond), b.breaker)
+}
+
+// newCache creates the cache backend selected by backend ("redis", "tiered" or "memory", default "redis")
+func newCache(backend string) cacheBackend {
+	switch backend {
+	case "memory":
+		// In-process LRU cache for local development and small single-instance deployments
+		memoryCache := cache.NewMemoryCache(utils.GetEnvInt("MEMORY_CACHE_MAX_BYTES", 64<<20))
+		return cacheBackend{cache: memoryCache, close: func() {}}
+
+	case "", "redis", "tiered":
+		// Initialize Redis client (connects lazily, so startup does not depend on Redis)
+		client := Redis.NewRedisClient(0)
+
+		// Create Redis cache wrapper, bounding every Redis call so a slow Redis can't hang requests,
+		// behind a circuit breaker that bypasses the cache while Redis is failing
+		breaker := cache.NewCircuitBreakerCache(
+			cache.NewRedisCache(client, utils.GetEnvDuration("REDIS_TIMEOUT", 200*time.Millisecond)),
+			utils.GetEnvInt("CACHE_BREAKER_FAILURES", 5),
+			utils.GetEnvDuration("CACHE_BREAKER_COOLDOWN", 10*time.Second),
+		)
+		if backend != "tiered" {
+			return cacheBackend{cache: breaker, breaker: breaker, redis: client, close: func() { client.Close() }}
+		}
+
+		// Keep hot short links in a small local cache in front of Redis,
+		// invalidated across instances through Redis pub/sub
+		tiered := cache.NewTieredCache(
+			cache.NewMemoryCache(utils.GetEnvInt("LOCAL_CACHE_MAX_BYTES", 16<<20)),
+			breaker,
+			cache.NewRedisInvalidator(client, "cache:invalidate"),
+			utils.GetEnvDuration("LOCAL_CACHE_TTL", 5*time.Second),
+			"short:", "expire:",
+		)
+		listenCtx, stopListening := context.WithCancel(context.Background())
+		go tiered.Listen(listenCtx)
+		expvar.Publish("cache_tiers", expvar.Func(func() any { return tiered.Stats() }))
+
+		return cacheBackend{cache: tiered, breaker: breaker, redis: client, close: func() {
+			stopListening()
+			client.Close()
+		}}
+
+	default:
+		slog.Error(" [cache_backend.go] [UNKNOWN CACHE BACKEND] ", slog.String("backend", backend))
+		panic("unknown CACHE_BACKEND " + backend) // Panic on misconfiguration
+	}
+}
@@ -20,7 +20,9 @@ func NewMysqlDb() (*sql.DB, error) {
 	user := os.Getenv("MYSQL_USER")
 	password := os.Getenv("MYSQL_PASSWORD")
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", user, password, host, port, databaseName)
+	// clientFoundRows makes UPDATE report the rows it matched rather than the rows it changed,
+	// so updating a row with its current values isn't mistaken for a missing row
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&clientFoundRows=true", user, password, host, port, databaseName)
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		slog.Error(" [db.go] [OPEN DB] ", slog.Any("error", err))
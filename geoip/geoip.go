@@ -0,0 +1,141 @@
+package geoip
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record holds the fields read from GeoIP2/GeoLite2 Country and City databases.
+type record struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Reader looks up countries in a MaxMind-format (.mmdb) database file,
+// reopening the file when it changes on disk.
+// A nil *Reader is valid and never finds a country.
+type Reader struct {
+	path    string            // Database file
+	mu      sync.RWMutex      // Guards db and modTime; lookups hold the read lock so a reload never closes a database in use
+	db      *maxminddb.Reader // Open database
+	modTime time.Time         // Modification time of the open file
+}
+
+// Open opens the database at path.
+func Open(path string) (*Reader, error) {
+	r := &Reader{path: path}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load reads the database file and replaces the current one.
+// The file is read into memory rather than mapped, so rewriting it in place can't corrupt lookups.
+func (r *Reader) load() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	db, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	old := r.db
+	r.db, r.modTime = db, info.ModTime()
+	r.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code (e.g., US) of the country of ip,
+// falling back to the country the network is registered in.
+// Returns an empty string if ip is invalid or not in the database.
+func (r *Reader) Country(ip string) string {
+	if r == nil {
+		return ""
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	var rec record
+	r.mu.RLock()
+	err := r.db.Lookup(parsed, &rec)
+	r.mu.RUnlock()
+	if err != nil {
+		slog.Error(" [geoip.go] [LOOKUP] ", slog.String("ip", ip), slog.Any("error", err))
+		return ""
+	}
+	if rec.Country.IsoCode != "" {
+		return strings.ToUpper(rec.Country.IsoCode)
+	}
+	return strings.ToUpper(rec.RegisteredCountry.IsoCode)
+}
+
+// Reload reopens the database if its file was modified since it was opened.
+// A file that can't be read is logged and the previous database is kept.
+func (r *Reader) Reload() {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		slog.Error(" [geoip.go] [RELOAD] ", slog.String("path", r.path), slog.Any("error", err))
+		return
+	}
+	r.mu.RLock()
+	unchanged := info.ModTime().Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		slog.Error(" [geoip.go] [RELOAD] ", slog.String("path", r.path), slog.Any("error", err))
+		return
+	}
+	slog.Info(" [geoip.go] [RELOAD] ", slog.String("path", r.path))
+}
+
+// ReloadEvery checks the database file for changes every interval until ctx is cancelled.
+func (r *Reader) ReloadEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reload()
+		}
+	}
+}
+
+// Close closes the database.
+func (r *Reader) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.db.Close()
+}
@@ -0,0 +1,62 @@
+package geoip_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"urlshortener/geoip"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDatabase writes a Country database mapping each network to an ISO code
+func writeDatabase(t *testing.T, path string, networks map[string]string) {
+	tree, err := mmdbwriter.New(mmdbwriter.Options{DatabaseType: "GeoLite2-Country", RecordSize: 24})
+	require.NoError(t, err)
+	for cidr, isoCode := range networks {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		require.NoError(t, tree.Insert(network, mmdbtype.Map{
+			"country": mmdbtype.Map{"iso_code": mmdbtype.String(isoCode)},
+		}))
+	}
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = tree.WriteTo(file)
+	require.NoError(t, err)
+}
+
+func TestReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	writeDatabase(t, path, map[string]string{"81.2.69.0/24": "GB", "2a02:8100::/32": "de"})
+
+	reader, err := geoip.Open(path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.Equal(t, "GB", reader.Country("81.2.69.142"))
+	require.Equal(t, "DE", reader.Country("2a02:8100::1"))
+	require.Equal(t, "", reader.Country("8.8.8.8"))
+	require.Equal(t, "", reader.Country("not an ip"))
+
+	// A replaced file is picked up on the next reload
+	writeDatabase(t, path, map[string]string{"81.2.69.0/24": "FR"})
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	reader.Reload()
+	require.Equal(t, "FR", reader.Country("81.2.69.142"))
+
+	// A broken file keeps the previous database
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute)))
+	reader.Reload()
+	require.Equal(t, "FR", reader.Country("81.2.69.142"))
+
+	var disabled *geoip.Reader
+	require.Equal(t, "", disabled.Country("81.2.69.142"))
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"strconv"
+	"urlshortener/middleware"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler handles HTTP requests about accounts and their quotas
+type AccountHandler struct {
+	AccountService *services.AccountService // Service for account operations
+	QuotaService   *services.QuotaService   // Service for quota lookups
+}
+
+// CreateAccountRequest represents the expected JSON payload for creating an account
+type CreateAccountRequest struct {
+	Email string `json:"email" binding:"required"` // Contact email of the account
+	Plan  string `json:"plan,omitempty"`           // Quota plan (default free)
+	Admin bool   `json:"admin,omitempty"`          // Whether the account may manage other accounts
+}
+
+// SetQuotaRequest represents the expected JSON payload for changing the quota of an account
+// Omitted quotas use the plan's, 0 makes them unlimited
+type SetQuotaRequest struct {
+	Plan    string `json:"plan" binding:"required"` // Quota plan
+	Daily   *int   `json:"daily_quota,omitempty"`   // Daily link quota override (optional)
+	Monthly *int   `json:"monthly_quota,omitempty"` // Monthly link quota override (optional)
+}
+
+// NewAccountHandler creates a new AccountHandler with the given services
+func NewAccountHandler(accountService *services.AccountService, quotaService *services.QuotaService) *AccountHandler {
+	return &AccountHandler{
+		AccountService: accountService,
+		QuotaService:   quotaService,
+	}
+}
+
+// GetQuota handles GET /me/quota requests
+// Returns the plan and the daily and monthly quota of the client (by IP address for anonymous clients)
+func (a *AccountHandler) GetQuota(ctx *gin.Context) {
+	usage, err := a.QuotaService.Usage(ctx.Request.Context(), middleware.CurrentAccount(ctx), nil, ctx.ClientIP())
+	if err != nil {
+		ctx.Error(utils.ErrCacheUnavailable)
+		return
+	}
+
+	middleware.SetQuotaHeaders(ctx, usage)
+	ctx.JSON(200, usage)
+}
+
+// CreateAccount handles POST /admin/accounts requests
+// Returns the new account along with its API key, which is only shown once
+func (a *AccountHandler) CreateAccount(ctx *gin.Context) {
+	var req CreateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+	if req.Plan == "" {
+		req.Plan = "free"
+	}
+
+	account, apiKey, err := a.AccountService.CreateAccount(ctx.Request.Context(), req.Email, req.Plan, req.Admin)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(201, gin.H{
+		"id":      account.Id,
+		"email":   account.Email,
+		"plan":    account.Plan,
+		"admin":   account.Admin,
+		"api_key": apiKey,
+	})
+}
+
+// SetQuota handles PUT /admin/accounts/:id/quota requests
+// Changes the plan of an account and overrides its daily and monthly quotas
+func (a *AccountHandler) SetQuota(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(utils.ErrAccountNotFound)
+		return
+	}
+
+	var req SetQuotaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	account, err := a.AccountService.SetQuota(ctx.Request.Context(), id, req.Plan, req.Daily, req.Monthly)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"id":            account.Id,
+		"plan":          account.Plan,
+		"daily_quota":   account.DailyQuota,
+		"monthly_quota": account.MonthlyQuota,
+	})
+}
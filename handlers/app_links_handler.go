@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AppLinksConfig identifies the mobile apps allowed to open short links directly (universal links and Android App Links)
+type AppLinksConfig struct {
+	AppleAppIds         []string // iOS apps as <team id>.<bundle id> (e.g., ABCDE12345.com.example.app)
+	AndroidPackage      string   // Package name of the Android app (e.g., com.example.app)
+	AndroidFingerprints []string // SHA-256 fingerprints of the Android app's signing certificates
+}
+
+// AppLinksHandler serves the association files that let mobile apps claim the short link domain
+type AppLinksHandler struct {
+	appleAssociation []byte // apple-app-site-association document
+	assetLinks       []byte // assetlinks.json document
+}
+
+// appleAssociation is the apple-app-site-association document
+type appleAssociation struct {
+	AppLinks struct {
+		Details []appleAppDetail `json:"details"`
+	} `json:"applinks"`
+}
+
+// appleAppDetail lists the apps and paths of an apple-app-site-association document
+type appleAppDetail struct {
+	AppIds     []string            `json:"appIDs"`
+	Components []map[string]string `json:"components"`
+}
+
+// assetLink is a statement of assetlinks.json
+type assetLink struct {
+	Relation []string `json:"relation"`
+	Target   struct {
+		Namespace    string   `json:"namespace"`
+		PackageName  string   `json:"package_name"`
+		Fingerprints []string `json:"sha256_cert_fingerprints"`
+	} `json:"target"`
+}
+
+// NewAppLinksHandler creates a new AppLinksHandler from config
+// Apps that aren't configured are left out, so the documents are empty but valid when nothing is configured
+func NewAppLinksHandler(config AppLinksConfig) (*AppLinksHandler, error) {
+	var apple appleAssociation
+	apple.AppLinks.Details = []appleAppDetail{}
+	if len(config.AppleAppIds) > 0 {
+		apple.AppLinks.Details = append(apple.AppLinks.Details, appleAppDetail{
+			AppIds:     config.AppleAppIds,
+			Components: []map[string]string{{"/": "*"}}, // Every short link
+		})
+	}
+
+	android := []assetLink{}
+	if config.AndroidPackage != "" {
+		var link assetLink
+		link.Relation = []string{"delegate_permission/common.handle_all_urls"}
+		link.Target.Namespace = "android_app"
+		link.Target.PackageName = config.AndroidPackage
+		link.Target.Fingerprints = config.AndroidFingerprints
+		android = append(android, link)
+	}
+
+	appleJson, err := json.Marshal(apple)
+	if err != nil {
+		return nil, err
+	}
+	androidJson, err := json.Marshal(android)
+	if err != nil {
+		return nil, err
+	}
+	return &AppLinksHandler{
+		appleAssociation: appleJson,
+		assetLinks:       androidJson,
+	}, nil
+}
+
+// GetAppleAppSiteAssociation handles GET /.well-known/apple-app-site-association requests
+func (a *AppLinksHandler) GetAppleAppSiteAssociation(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.Data(200, "application/json", a.appleAssociation)
+}
+
+// GetAssetLinks handles GET /.well-known/assetlinks.json requests
+func (a *AppLinksHandler) GetAssetLinks(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.Data(200, "application/json", a.assetLinks)
+}
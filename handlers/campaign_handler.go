@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignHandler handles HTTP requests about campaigns and their analytics
+type CampaignHandler struct {
+	CampaignService *services.CampaignService // Service for campaign operations
+}
+
+// CreateCampaignRequest represents the expected JSON payload for creating a campaign
+type CreateCampaignRequest struct {
+	Name string     `json:"name" binding:"required"` // Name, unique per account
+	Utm  models.UTM `json:"utm"`                     // UTM parameters the campaign's links default to (optional)
+}
+
+// NewCampaignHandler creates a new CampaignHandler with the given CampaignService
+func NewCampaignHandler(campaignService *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		CampaignService: campaignService,
+	}
+}
+
+// CreateCampaign handles POST /campaigns requests
+// Creates a campaign owned by the authenticated account
+func (c *CampaignHandler) CreateCampaign(ctx *gin.Context) {
+	var req CreateCampaignRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	campaign, err := c.CampaignService.CreateCampaign(ctx.Request.Context(), middleware.CurrentAccount(ctx), req.Name, req.Utm)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(201, campaign)
+}
+
+// GetCampaign handles GET /campaigns/:id requests
+// Returns the campaign and its UTM template
+func (c *CampaignHandler) GetCampaign(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(utils.ErrCampaignNotFound)
+		return
+	}
+
+	campaign, err := c.CampaignService.GetCampaign(ctx.Request.Context(), middleware.CurrentAccount(ctx), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, campaign)
+}
+
+// GetCampaignStats handles GET /campaigns/:id/stats requests
+// Returns the number of links of the campaign and their clicks, in total, by link, by country and by device class
+func (c *CampaignHandler) GetCampaignStats(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(utils.ErrCampaignNotFound)
+		return
+	}
+
+	stats, err := c.CampaignService.GetStats(ctx.Request.Context(), middleware.CurrentAccount(ctx), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
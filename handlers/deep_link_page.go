@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"html/template"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bounceTemplate renders the page that tries to open an app before falling back to the web destination
+var bounceTemplate = template.Must(template.ParseFS(templates, "templates/bounce.html"))
+
+// bounceFallbackDelay is the time in milliseconds the bounce page waits for the app to open before loading the web destination
+const bounceFallbackDelay = 1500
+
+// bouncePage holds the fields of the deep link bounce page
+type bouncePage struct {
+	AppURI        template.URL // App URI, validated when the link was created (custom schemes would be filtered otherwise)
+	Fallback      string       // Web destination loaded if the app doesn't open
+	FallbackDelay int          // Milliseconds to wait before loading Fallback
+}
+
+// canBounce reports whether the bounce page may load fallback from script, which is only safe for web URLs
+func canBounce(fallback string) bool {
+	parsed, err := url.Parse(fallback)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
+
+// renderBounce renders the bounce page of a deep link
+func renderBounce(ctx *gin.Context, appURI string, fallback string) {
+	page := bouncePage{
+		AppURI:        template.URL(appURI),
+		Fallback:      fallback,
+		FallbackDelay: bounceFallbackDelay,
+	}
+
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Header("Vary", "User-Agent")
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Status(200)
+	if err := bounceTemplate.Execute(ctx.Writer, page); err != nil {
+		ctx.Error(err)
+	}
+}
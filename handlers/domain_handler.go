@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"strconv"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DomainHandler handles HTTP requests about custom domains
+type DomainHandler struct {
+	DomainService *services.DomainService // Service for domain operations
+}
+
+// AddDomainRequest represents the expected JSON payload for adding a custom domain
+type AddDomainRequest struct {
+	Host string `json:"host" binding:"required"` // Host name serving the links (e.g., go.example.com)
+}
+
+// NewDomainHandler creates a new DomainHandler with the given DomainService
+func NewDomainHandler(domainService *services.DomainService) *DomainHandler {
+	return &DomainHandler{
+		DomainService: domainService,
+	}
+}
+
+// domainResponse renders a domain along with the DNS record proving its ownership
+func domainResponse(domain *models.Domain) gin.H {
+	name, value := domain.VerificationRecord()
+	return gin.H{
+		"id":          domain.Id,
+		"host":        domain.Host,
+		"verified":    domain.Verified(),
+		"verified_at": domain.VerifiedAt,
+		"created_at":  domain.CreatedAt,
+		"verification_record": gin.H{
+			"type":  "TXT",
+			"name":  name,
+			"value": value,
+		},
+		"short_url_prefix": shortUrlPrefix(domain.Host),
+	}
+}
+
+// AddDomain handles POST /domains requests
+// Adds a custom domain to the authenticated account; links can use it once its TXT record is verified
+func (d *DomainHandler) AddDomain(ctx *gin.Context) {
+	var req AddDomainRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	domain, err := d.DomainService.AddDomain(ctx.Request.Context(), middleware.CurrentAccount(ctx), req.Host)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(201, domainResponse(domain))
+}
+
+// ListDomains handles GET /domains requests
+// Returns the custom domains of the authenticated account
+func (d *DomainHandler) ListDomains(ctx *gin.Context) {
+	domains, err := d.DomainService.ListDomains(ctx.Request.Context(), middleware.CurrentAccount(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]gin.H, 0, len(domains))
+	for i := range domains {
+		response = append(response, domainResponse(&domains[i]))
+	}
+	ctx.JSON(200, response)
+}
+
+// VerifyDomain handles POST /domains/:id/verify requests
+// Looks up the verification TXT record of the domain and starts serving it if the token matches
+func (d *DomainHandler) VerifyDomain(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(utils.ErrDomainNotFound)
+		return
+	}
+
+	domain, err := d.DomainService.VerifyDomain(ctx.Request.Context(), middleware.CurrentAccount(ctx), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, domainResponse(domain))
+}
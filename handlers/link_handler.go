@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultPageSize and maxPageSize bound the number of items returned by paginated listings
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// LinkHandler handles HTTP requests listing and searching the links of accounts and workspaces
+type LinkHandler struct {
+	SearchService *services.SearchService // Service for link searches
+}
+
+// NewLinkHandler creates a new LinkHandler with the given service
+func NewLinkHandler(searchService *services.SearchService) *LinkHandler {
+	return &LinkHandler{
+		SearchService: searchService,
+	}
+}
+
+// pageParams reads the limit and cursor query parameters of a paginated listing
+// limit defaults to defaultPageSize and is capped at maxPageSize
+func pageParams(ctx *gin.Context) (limit int, cursor int, err error) {
+	limit, cursor = defaultPageSize, 0
+	if value := ctx.Query("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			return 0, 0, utils.ErrValidation
+		}
+		limit = min(limit, maxPageSize)
+	}
+	if value := ctx.Query("cursor"); value != "" {
+		if cursor, err = strconv.Atoi(value); err != nil || cursor < 1 {
+			return 0, 0, utils.ErrValidation
+		}
+	}
+	return limit, cursor, nil
+}
+
+// linkFilter reads the filters of a link listing from the query string:
+// q, tag (repeatable, links must have every tag), domain, folder, created_after and created_before (RFC 3339),
+// expiry (active, expired or never), created_by, workspace_id, limit and cursor
+func linkFilter(ctx *gin.Context) (models.LinkFilter, error) {
+	var filter models.LinkFilter
+	var err error
+	if filter.Limit, filter.Before, err = pageParams(ctx); err != nil {
+		return filter, err
+	}
+	filter.Query = ctx.Query("q")
+	filter.Tags = ctx.QueryArray("tag")
+	filter.Expiry = ctx.Query("expiry")
+	if value, ok := ctx.GetQuery("domain"); ok {
+		domain := services.NormalizeHost(value)
+		filter.Domain = &domain
+	}
+	if value, ok := ctx.GetQuery("folder"); ok {
+		filter.Folder = &value
+	}
+	for name, field := range map[string]**time.Time{"created_after": &filter.CreatedAfter, "created_before": &filter.CreatedBefore} {
+		if value := ctx.Query(name); value != "" {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, utils.ErrValidation
+			}
+			*field = &at
+		}
+	}
+	for name, field := range map[string]**int{"created_by": &filter.CreatedBy, "workspace_id": &filter.WorkspaceId} {
+		if value := ctx.Query(name); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, utils.ErrValidation
+			}
+			*field = &id
+		}
+	}
+	return filter, nil
+}
+
+// listLinks searches the links matching filter and renders a page of them, newest first;
+// next_cursor fetches the following page (null on the last one)
+func (l *LinkHandler) listLinks(ctx *gin.Context, filter models.LinkFilter) {
+	urls, next, err := l.SearchService.Search(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	links := make([]gin.H, 0, len(urls))
+	for i := range urls {
+		links = append(links, linkResponse(&urls[i]))
+	}
+	var nextCursor *int
+	if next != 0 {
+		nextCursor = &next
+	}
+	ctx.JSON(200, gin.H{
+		"links":       links,
+		"next_cursor": nextCursor,
+	})
+}
+
+// SearchLinks handles GET /links requests
+// Returns a page of the links created by the authenticated account or in its workspaces that match the
+// filters of linkFilter, with q matched against the destination and title; admins search every link
+func (l *LinkHandler) SearchLinks(ctx *gin.Context) {
+	filter, err := linkFilter(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if account := middleware.CurrentAccount(ctx); !account.Admin {
+		filter.VisibleTo = &account.Id
+	}
+	l.listLinks(ctx, filter)
+}
+
+// ListWorkspaceLinks handles GET /workspaces/:id/links requests
+// Returns a page of the links of the workspace, accepting the same filters as SearchLinks
+func (l *LinkHandler) ListWorkspaceLinks(ctx *gin.Context) {
+	filter, err := linkFilter(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	filter.WorkspaceId = &middleware.CurrentWorkspace(ctx).Id
+	l.listLinks(ctx, filter)
+}
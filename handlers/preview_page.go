@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"net/url"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+// previewTemplate renders the link preview page
+var previewTemplate = template.Must(template.ParseFS(templates, "templates/preview.html"))
+
+// previewPage holds the fields shown on the link preview page
+type previewPage struct {
+	ShortCode   string     // Short code of the link
+	Destination string     // Original URL
+	Domain      string     // Host of the original URL
+	CreatedAt   time.Time  // When the link was created
+	ExpireAt    *time.Time // When the link expires (nil if it never does)
+	Warnings    []string   // Safety warnings about the destination
+}
+
+// renderPreview renders the preview page of a link instead of redirecting to it
+func renderPreview(ctx *gin.Context, shortCode string, link *models.Url) {
+	page := previewPage{
+		ShortCode:   shortCode,
+		Destination: link.URL,
+		CreatedAt:   link.CreatedAt,
+		Warnings:    utils.SafetyWarnings(link.URL),
+	}
+	if destination, err := url.Parse(link.URL); err == nil {
+		page.Domain = destination.Hostname()
+	}
+	if link.Expire != link.CreatedAt {
+		page.ExpireAt = &link.Expire
+	}
+
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Status(200)
+	if err := previewTemplate.Execute(ctx.Writer, page); err != nil {
+		ctx.Error(err)
+	}
+}
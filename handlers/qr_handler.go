@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/models"
+	"urlshortener/qr"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// qrCacheTTL is how long a rendered QR code stays in cache
+const qrCacheTTL = 24 * time.Hour
+
+// QrHandler handles HTTP requests for QR codes of short URLs
+type QrHandler struct {
+	UrlService    *services.UrlService    // Service for URL lookups
+	DomainService *services.DomainService // Custom domains short codes are resolved from
+	cache         cache.Cache             // Rendered images keyed by link and options
+}
+
+// NewQrHandler creates a new QrHandler with the given services and cache
+func NewQrHandler(urlService *services.UrlService, domainService *services.DomainService, cache cache.Cache) *QrHandler {
+	return &QrHandler{
+		UrlService:    urlService,
+		DomainService: domainService,
+		cache:         cache,
+	}
+}
+
+// parseQrOptions reads the QR code options from the query string, falling back to qr.DefaultOptions
+// Returns utils.ErrValidation if an option is malformed or out of range
+func parseQrOptions(ctx *gin.Context) (qr.Options, error) {
+	opts := qr.DefaultOptions
+	if format := ctx.Query("format"); format != "" {
+		opts.Format = qr.Format(format)
+	}
+	if level := ctx.Query("level"); level != "" {
+		opts.Level = level
+	}
+
+	var err error
+	if size := ctx.Query("size"); size != "" {
+		if opts.Size, err = strconv.Atoi(size); err != nil {
+			return opts, utils.ErrValidation
+		}
+	}
+	if margin := ctx.Query("margin"); margin != "" {
+		if opts.Margin, err = strconv.Atoi(margin); err != nil {
+			return opts, utils.ErrValidation
+		}
+	}
+	if fg := ctx.Query("fg"); fg != "" {
+		if opts.Foreground, err = qr.ParseColor(fg); err != nil {
+			return opts, utils.ErrValidation
+		}
+	}
+	if bg := ctx.Query("bg"); bg != "" {
+		if opts.Background, err = qr.ParseColor(bg); err != nil {
+			return opts, utils.ErrValidation
+		}
+	}
+
+	if opts.Validate() != nil {
+		return opts, utils.ErrValidation
+	}
+	return opts, nil
+}
+
+// GetQrCode handles GET /qr/:code requests
+// Returns a PNG or SVG QR code of the full short URL, rendered with the size, margin,
+// error correction level and colors from the query string and cached for later requests
+func (q *QrHandler) GetQrCode(ctx *gin.Context) {
+	shortCode := ctx.Param("code")
+	if shortCode == "" {
+		ctx.Error(utils.ErrShortCodeRequired)
+		return
+	}
+
+	opts, err := parseQrOptions(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Only render codes that exist
+	domain := q.DomainService.Match(ctx.Request.Host)
+	if _, err := q.UrlService.GetUrlByCode(ctx.Request.Context(), models.LinkKey(domain, shortCode)); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	key := "qr:" + models.LinkKey(domain, shortCode) + ":" + opts.Key()
+	image, err := q.cache.Get(ctx.Request.Context(), key)
+	if err != nil {
+		rendered, err := qr.Render(shortUrlPrefix(domain)+shortCode, opts)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		image = string(rendered)
+		if err := q.cache.Set(ctx.Request.Context(), key, image, qrCacheTTL); err != nil {
+			slog.Warn(" [qr_handler.go] [CACHE QR CODE] ", slog.String("shortCode", shortCode), slog.Any("error", err))
+		}
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(200, opts.Format.ContentType(), []byte(image))
+}
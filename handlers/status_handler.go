@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"urlshortener/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusHandler reports the health of the service and its backends
+type StatusHandler struct {
+	db    *sql.DB                    // MySQL connection to ping
+	cache *cache.CircuitBreakerCache // Breaker guarding Redis (nil when using the in-memory cache)
+}
+
+// NewStatusHandler creates a new StatusHandler for the given database and cache breaker
+// breaker may be nil if the cache cannot fail (e.g., the in-memory cache)
+func NewStatusHandler(db *sql.DB, cache *cache.CircuitBreakerCache) *StatusHandler {
+	return &StatusHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// GetStatus handles GET /status requests
+// Returns 200 with status "ok" when every backend is healthy, 200 with "degraded" when only the
+// cache is down (requests fall back to MySQL), and 503 with "down" when MySQL is unreachable
+func (s *StatusHandler) GetStatus(ctx *gin.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	databaseUp := s.db.PingContext(pingCtx) == nil
+	cacheBackend, cacheState := "memory", cache.BreakerClosed
+	if s.cache != nil {
+		cacheBackend, cacheState = "redis", s.cache.State()
+	}
+
+	status, code := "ok", 200
+	switch {
+	case !databaseUp:
+		status, code = "down", 503
+	case cacheState != cache.BreakerClosed:
+		status = "degraded"
+	}
+
+	ctx.JSON(code, gin.H{
+		"status": status,
+		"database": gin.H{
+			"healthy": databaseUp,
+		},
+		"cache": gin.H{
+			"backend": cacheBackend,
+			"healthy": cacheState == cache.BreakerClosed,
+			"breaker": cacheState,
+		},
+	})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
-	"net/url"
+	"errors"
+	"net/http"
 	"os"
+	"strings"
+	"urlshortener/middleware"
+	"urlshortener/models"
 	"urlshortener/services"
 	"urlshortener/utils"
 
@@ -12,136 +16,422 @@ import (
 // ShortenHandler handles HTTP requests for URL shortening and redirection
 // It uses a UrlService to perform business logic
 type ShortenHandler struct {
-	UrlService *services.UrlService // Service for URL operations
+	UrlService       *services.UrlService       // Service for URL operations
+	ClickService     *services.ClickService     // Click analytics of redirects
+	CampaignService  *services.CampaignService  // Campaigns links can be added to
+	DomainService    *services.DomainService    // Custom domains links are created on and resolved from
+	WorkspaceService *services.WorkspaceService // Workspaces whose members may see the stats of their links
 }
 
 // UrlRequest represents the expected JSON payload for shortening a URL
 // ExpireAt is optional and specifies expiration in minutes
 type UrlRequest struct {
-	Url      string `json:"url" binding:"required"` // The original URL to shorten
-	ExpireAt int64  `json:"expire_in,omitempty"`    // Expiration in minutes (optional)
+	Url          string               `json:"url" binding:"required"`  // The original URL to shorten
+	ExpireAt     int64                `json:"expire_in,omitempty"`     // Expiration in minutes (optional)
+	Preview      bool                 `json:"preview,omitempty"`       // Always show the preview page instead of redirecting (optional)
+	Rules        []models.RoutingRule `json:"rules,omitempty"`         // Alternate destinations chosen by the visitor's User-Agent (optional)
+	Countries    map[string]string    `json:"countries,omitempty"`     // Alternate destinations by ISO country code of the visitor (optional)
+	Split        *models.Split        `json:"split,omitempty"`         // Weighted destinations for A/B tests (optional)
+	ForwardQuery bool                 `json:"forward_query,omitempty"` // Merge the query string of the request into the destination (optional)
+	ForwardPath  bool                 `json:"forward_path,omitempty"`  // Append path segments following the short code to the destination (optional)
+	Utm          *models.UTM          `json:"utm,omitempty"`           // UTM parameters added to every destination (optional)
+	CampaignId   *int                 `json:"campaign_id,omitempty"`   // Campaign of the link, whose UTM template fills in missing parameters (optional)
+	DeepLink     *models.DeepLink     `json:"deep_link,omitempty"`     // App URIs tried on mobile before the web destination (optional)
+	Domain       string               `json:"domain,omitempty"`        // Verified custom domain of the account to create the link on (optional)
+
+	Title  string   `json:"title,omitempty" binding:"max=255"`  // Title shown in link listings and matched by search (optional)
+	Notes  string   `json:"notes,omitempty" binding:"max=4096"` // Free-form notes (optional)
+	Folder string   `json:"folder,omitempty" binding:"max=255"` // Folder the link is filed under (optional)
+	Tags   []string `json:"tags,omitempty"`                     // Up to 20 tags, lower-cased (optional)
+}
+
+// UpdateLinkRequest represents the expected JSON payload for changing a link of a workspace
+// Omitted fields are left unchanged
+type UpdateLinkRequest struct {
+	Url      *string `json:"url,omitempty"`       // New original URL (optional)
+	ExpireIn *int64  `json:"expire_in,omitempty"` // New expiration in minutes from now, 0 removes it (optional)
+
+	Title  *string   `json:"title,omitempty" binding:"omitempty,max=255"`  // New title, "" removes it (optional)
+	Notes  *string   `json:"notes,omitempty" binding:"omitempty,max=4096"` // New notes, "" removes them (optional)
+	Folder *string   `json:"folder,omitempty" binding:"omitempty,max=255"` // New folder, "" moves the link out of folders (optional)
+	Tags   *[]string `json:"tags,omitempty"`                               // Tags replacing the current ones, [] removes them (optional)
 }
 
-// NewShortenHandler creates a new ShortenHandler with the given UrlService
-func NewShortenHandler(UrlService *services.UrlService) *ShortenHandler {
+// NewShortenHandler creates a new ShortenHandler with the given services
+func NewShortenHandler(UrlService *services.UrlService, ClickService *services.ClickService, CampaignService *services.CampaignService, DomainService *services.DomainService, WorkspaceService *services.WorkspaceService) *ShortenHandler {
 	return &ShortenHandler{
-		UrlService: UrlService,
+		UrlService:       UrlService,
+		ClickService:     ClickService,
+		CampaignService:  CampaignService,
+		DomainService:    DomainService,
+		WorkspaceService: WorkspaceService,
 	}
 }
 
-// ShortenURL handles POST /shorten requests to create a new short URL
+// linkKey returns the models.LinkKey of a short code on the domain the request was sent to
+// Hosts other than verified custom domains resolve to the default domain
+func (s *ShortenHandler) linkKey(ctx *gin.Context, shortCode string) string {
+	return models.LinkKey(s.DomainService.Match(ctx.Request.Host), shortCode)
+}
+
+// shortUrlPrefix returns the base URL of the short links of a domain, SHORT_URL_PREFIX for the default domain
+// Custom domains use the scheme of SHORT_URL_PREFIX, so deployments without TLS don't hand out https links
+func shortUrlPrefix(domain string) string {
+	prefix := os.Getenv("SHORT_URL_PREFIX")
+	if domain == "" {
+		return prefix
+	}
+	scheme, _, found := strings.Cut(prefix, "://")
+	if !found || scheme == "" {
+		scheme = "https"
+	}
+	return scheme + "://" + domain + "/"
+}
+
+// ShortenURL handles POST /shorten and POST /workspaces/:id/links requests to create a new short URL
 // Validates input, calls the service, and returns the result as JSON
+// Links created behind middleware.RequireWorkspaceRole belong to the workspace
+// Failures are reported with ctx.Error and rendered by middleware.ErrorMiddleware
 func (s *ShortenHandler) ShortenURL(ctx *gin.Context) {
 	var req UrlRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(422, gin.H{
-			"error": "Validation error",
-		})
+		ctx.Error(utils.ErrValidation)
 		return
 	}
 
-	// Validate URL format
-	u, err := url.ParseRequestURI(req.Url)
-	if err != nil || u.Scheme == "" || u.Host == "" {
-		ctx.JSON(400, gin.H{
-			"error": "Invalid URL format",
-		})
+	// Validate URL format and length (greater than 25 characters)
+	if err := utils.ValidateUrl(req.Url); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	// Check if URL length is valid (greater than 25 characters)
-	if len(req.Url) <= 25 {
-		ctx.JSON(400, gin.H{
-			"error": "URL must be longer than 25 characters",
-		})
+	// Only verified domains and campaigns of the authenticated account can be used
+	domain, err := s.DomainService.LinkDomain(ctx.Request.Context(), middleware.CurrentAccount(ctx), req.Domain)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
+	var campaign *models.Campaign
+	if req.CampaignId != nil {
+		campaign, err = s.CampaignService.GetCampaign(ctx.Request.Context(), middleware.CurrentAccount(ctx), *req.CampaignId)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	var createdBy, workspaceId *int
+	if account := middleware.CurrentAccount(ctx); account != nil {
+		createdBy = &account.Id
+	}
+	if workspace := middleware.CurrentWorkspace(ctx); workspace != nil {
+		workspaceId = &workspace.Id
+	}
 
 	// Create the short URL using the service
-	short, expireAt, err := s.UrlService.CreateShortUrl(req.Url, req.ExpireAt, ctx.Request.UserAgent())
+	short, expireAt, err := s.UrlService.CreateShortUrl(ctx.Request.Context(), req.Url, req.ExpireAt, ctx.Request.UserAgent(), services.LinkOptions{
+		Preview:      req.Preview,
+		Rules:        req.Rules,
+		Countries:    req.Countries,
+		Split:        req.Split,
+		ForwardQuery: req.ForwardQuery,
+		ForwardPath:  req.ForwardPath,
+		UTM:          req.Utm,
+		Campaign:     campaign,
+		DeepLink:     req.DeepLink,
+		Domain:       domain,
+		WorkspaceId:  workspaceId,
+		CreatedBy:    createdBy,
+		Title:        req.Title,
+		Notes:        req.Notes,
+		Folder:       req.Folder,
+		Tags:         req.Tags,
+	})
 	if err != nil {
-		ctx.JSON(500, gin.H{
-			"error": "Internal server error",
-		})
+		ctx.Error(err)
 		return
 	}
 
-	// Build the full short URL with prefix
-	prefix := os.Getenv("SHORT_URL_PREFIX")
+	// Build the full short URL with the prefix of its domain
 	ctx.JSON(201, gin.H{
 		"message":   "success",
-		"short_url": prefix + short,
+		"short_url": shortUrlPrefix(domain) + short,
 		"expire_at": expireAt,
 	})
 }
 
-// GetFullURL handles GET /:code requests to redirect to the original URL
-// Looks up the short code and redirects, or returns an error if not found
+// GetFullURL handles GET /:code and GET /:code/*path requests to redirect to the original URL
+// Looks up the short code and redirects, or reports an error if not found or expired
+// A "+" suffix (e.g., /abc123+), or a link created with preview, shows the preview page instead
+// Redirects are temporary and uncached, since links can be edited and every visit is counted as a click
+// Links with routing rules, country destinations or a split redirect to the destination chosen for the visitor
+// Sticky splits remember the variant of the visitor in a cookie scoped to the short code
+// Links created with forward_query or forward_path carry the query string and trailing path over to the destination
+// (trailing path segments are ignored otherwise)
+// Links with a deep link serve iOS and Android visitors a bounce page that opens the app and falls back to the web destination
+// Every redirect is recorded as a click in the background
 func (s *ShortenHandler) GetFullURL(ctx *gin.Context) {
-	shortCode := ctx.Param("code")
+	shortCode, preview := strings.CutSuffix(ctx.Param("code"), "+")
 	if shortCode == "" {
-		ctx.JSON(400, gin.H{
-			"error": "Short URL code is required",
-		})
+		ctx.Error(utils.ErrShortCodeRequired)
 		return
 	}
-	url, err := s.UrlService.GetUrlByCode(shortCode)
 
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), s.linkKey(ctx, shortCode))
 	if err != nil {
-		errMsg := "Internal server error"
-		errCode := 500
-		switch err {
-		case utils.ErrUrlNotFound:
-			errMsg, errCode = "URL not found", 404
-
-		case utils.ErrShortCodeExpired:
-			errMsg, errCode = "URL has expired", 410
-		}
+		ctx.Error(err)
+		return
+	}
+
+	if preview || url.Preview {
+		renderPreview(ctx, shortCode, url)
+		return
+	}
+
+	visit := s.UrlService.NewVisit(ctx.Request.UserAgent(), ctx.ClientIP(), ctx.Request.Referer())
+	if url.Split != nil && url.Split.Sticky {
+		visit.Variant, _ = ctx.Cookie(variantCookie(shortCode))
+	}
+	target := s.UrlService.Destination(url, visit)
+	s.ClickService.Record(url.Key(), target, visit)
+	destination := services.Passthrough(url, target.URL, ctx.Param("path"), ctx.Request.URL.RawQuery)
+	if !url.Targeted() {
+		ctx.Header("Cache-Control", "private, no-cache")
+		ctx.Redirect(302, destination)
+		return
+	}
 
-		ctx.JSON(errCode, gin.H{
-			"error": errMsg,
-		})
+	if url.Split != nil && url.Split.Sticky && target.Variant != "" && target.Variant != visit.Variant {
+		ctx.SetSameSite(http.SameSiteLaxMode)
+		ctx.SetCookie(variantCookie(shortCode), target.Variant, variantCookieMaxAge, "/"+shortCode, "", ctx.Request.TLS != nil, true)
+	}
+	if appURI := url.DeepLink.AppURI(visit.Agent); appURI != "" && canBounce(destination) {
+		renderBounce(ctx, appURI, destination)
 		return
 	}
+	ctx.Header("Vary", "User-Agent")
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Redirect(302, destination)
+}
 
-	ctx.Redirect(301, url.URL)
+// variantCookieMaxAge is the lifetime in seconds of the cookie holding the split variant of a visitor (30 days)
+const variantCookieMaxAge = 30 * 24 * 60 * 60
+
+// variantCookie returns the name of the cookie holding the split variant of a visitor for a short code
+func variantCookie(shortCode string) string {
+	return "variant_" + shortCode
+}
+
+// GetPreview handles GET /preview/:code requests
+// Renders an HTML page showing where the link goes instead of redirecting
+func (s *ShortenHandler) GetPreview(ctx *gin.Context) {
+	shortCode := ctx.Param("code")
+	if shortCode == "" {
+		ctx.Error(utils.ErrShortCodeRequired)
+		return
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), s.linkKey(ctx, shortCode))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	renderPreview(ctx, shortCode, url)
 }
 
 // GetUrlMetadata handles GET /fetch/:code requests to retrieve URL metadata
 // Looks up the short code and returns metadata without redirecting
+// The metadata of workspace links is only returned to members of the workspace
 func (s *ShortenHandler) GetUrlMetadata(ctx *gin.Context) {
 	shortCode := ctx.Param("code")
 	if shortCode == "" {
-		ctx.JSON(400, gin.H{
-			"error": "Short URL code is required",
-		})
+		ctx.Error(utils.ErrShortCodeRequired)
 		return
 	}
-	url, err := s.UrlService.GetUrlByCode(shortCode)
-
-	if err != nil && err != utils.ErrUrlNotFound {
-		errMsg := "Internal server error"
-		errCode := 500
-		switch err {
-		case utils.ErrUrlNotFound:
-			errMsg, errCode = "URL not found", 404
 
-		// case utils.ErrShortCodeExpired:
-		// 	errMsg, errCode = "URL has expired", 410
-		}
-
-		ctx.JSON(errCode, gin.H{
-			"error": errMsg,
-		})
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), s.linkKey(ctx, shortCode))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := s.authorizeStats(ctx, url); err != nil {
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(200, gin.H{
 		"url": url.URL,
 		"metadata": gin.H{
-			"short_code": shortCode,
-			"created_at": url.CreatedAt,
-			"expire_at":  url.Expire,
+			"short_code":    shortCode,
+			"domain":        url.Domain,
+			"created_at":    url.CreatedAt,
+			"expire_at":     url.Expire,
+			"preview":       url.Preview,
+			"rules":         url.Rules,
+			"countries":     url.Countries,
+			"split":         url.Split,
+			"forward_query": url.ForwardQuery,
+			"forward_path":  url.ForwardPath,
+			"campaign_id":   url.CampaignId,
+			"deep_link":     url.DeepLink,
+			"workspace_id":  url.WorkspaceId,
 		},
 	})
-}
\ No newline at end of file
+}
+
+// authorizeStats checks that the client may see the analytics and metadata of a link
+// Links of workspaces are only visible to their members; they are reported as missing to anyone else
+func (s *ShortenHandler) authorizeStats(ctx *gin.Context, url *models.Url) error {
+	if url.WorkspaceId == nil {
+		return nil
+	}
+	_, _, err := s.WorkspaceService.Membership(ctx.Request.Context(), middleware.CurrentAccount(ctx), *url.WorkspaceId)
+	if errors.Is(err, utils.ErrWorkspaceNotFound) {
+		return utils.ErrUrlNotFound
+	}
+	return err
+}
+
+// GetStats handles GET /stats/:code requests
+// Returns the number of clicks of a short code, in total and by country, OS, device, browser and referrer
+// The stats of workspace links are only returned to members of the workspace
+func (s *ShortenHandler) GetStats(ctx *gin.Context) {
+	shortCode := ctx.Param("code")
+	if shortCode == "" {
+		ctx.Error(utils.ErrShortCodeRequired)
+		return
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), s.linkKey(ctx, shortCode))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := s.authorizeStats(ctx, url); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	stats, err := s.ClickService.GetStats(ctx.Request.Context(), s.linkKey(ctx, shortCode))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
+
+// GetVariantStats handles GET /stats/:code/variants requests
+// Returns the weight and number of clicks of each variant of a split link
+// The stats of workspace links are only returned to members of the workspace
+func (s *ShortenHandler) GetVariantStats(ctx *gin.Context) {
+	shortCode := ctx.Param("code")
+	if shortCode == "" {
+		ctx.Error(utils.ErrShortCodeRequired)
+		return
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), s.linkKey(ctx, shortCode))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := s.authorizeStats(ctx, url); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	stats, err := s.ClickService.GetVariantStats(ctx.Request.Context(), url)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
+
+// workspaceLink retrieves a link of the workspace of the request by its short code
+// Codes on custom domains are selected with the domain query parameter (e.g., ?domain=go.example.com)
+// Links of other workspaces are reported as missing
+func (s *ShortenHandler) workspaceLink(ctx *gin.Context) (*models.Url, error) {
+	shortCode := ctx.Param("code")
+	if shortCode == "" {
+		return nil, utils.ErrShortCodeRequired
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx.Request.Context(), models.LinkKey(services.NormalizeHost(ctx.Query("domain")), shortCode))
+	if err != nil {
+		return nil, err
+	}
+	if url.WorkspaceId == nil || *url.WorkspaceId != middleware.CurrentWorkspace(ctx).Id {
+		return nil, utils.ErrUrlNotFound
+	}
+	return url, nil
+}
+
+// UpdateLink handles PATCH /workspaces/:id/links/:code requests
+// Changes the original URL, expiration, labels and/or tags of a link of the workspace
+func (s *ShortenHandler) UpdateLink(ctx *gin.Context) {
+	var req UpdateLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	url, err := s.workspaceLink(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	updated, err := s.UrlService.UpdateUrl(ctx.Request.Context(), url.Key(), services.LinkUpdate{
+		Url:      req.Url,
+		ExpireIn: req.ExpireIn,
+		Title:    req.Title,
+		Notes:    req.Notes,
+		Folder:   req.Folder,
+		Tags:     req.Tags,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, linkResponse(updated))
+}
+
+// DeleteLink handles DELETE /workspaces/:id/links/:code requests
+// Removes a link of the workspace
+func (s *ShortenHandler) DeleteLink(ctx *gin.Context) {
+	url, err := s.workspaceLink(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := s.UrlService.DeleteUrl(ctx.Request.Context(), url.Key()); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// linkResponse renders a link listed or changed by one of its owners
+func linkResponse(url *models.Url) gin.H {
+	tags := url.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return gin.H{
+		"id":           url.Id,
+		"short_code":   url.ShortURL,
+		"short_url":    shortUrlPrefix(url.Domain) + url.ShortURL,
+		"url":          url.URL,
+		"domain":       url.Domain,
+		"created_at":   url.CreatedAt,
+		"expire_at":    url.Expire,
+		"campaign_id":  url.CampaignId,
+		"workspace_id": url.WorkspaceId,
+		"created_by":   url.CreatedBy,
+		"title":        url.Title,
+		"notes":        url.Notes,
+		"folder":       url.Folder,
+		"tags":         tags,
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/repositories"
+	"urlshortener/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUrlRepo serves links from a map keyed by models.LinkKey; only lookups are implemented
+type memoryUrlRepo struct {
+	repositories.UrlRepository
+	links map[string]models.Url
+}
+
+func (m *memoryUrlRepo) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	if url, ok := m.links[shortCode]; ok {
+		return &url, nil
+	}
+	return nil, nil
+}
+
+// memoryWorkspaceRepo serves workspaces and their members from maps; only lookups are implemented
+type memoryWorkspaceRepo struct {
+	repositories.WorkspaceRepository
+	workspaces map[int]models.Workspace
+	members    map[int][]models.Member
+}
+
+func (m *memoryWorkspaceRepo) GetById(ctx context.Context, id int) (*models.Workspace, error) {
+	if workspace, ok := m.workspaces[id]; ok {
+		return &workspace, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryWorkspaceRepo) GetMember(ctx context.Context, workspaceId int, accountId int) (*models.Member, error) {
+	for _, member := range m.members[workspaceId] {
+		if member.AccountId == accountId {
+			return &member, nil
+		}
+	}
+	return nil, nil
+}
+
+// newTestRouter mounts the link routes of a ShortenHandler serving links to account (nil for anonymous clients)
+func newTestRouter(links map[string]models.Url, workspaces *services.WorkspaceService, account *models.Account) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewShortenHandler(
+		services.NewUrlService(&memoryUrlRepo{links: links}, nil),
+		services.NewClickService(nil, 10),
+		nil,
+		services.NewDomainService(nil, nil),
+		workspaces,
+	)
+
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	if account != nil {
+		router.Use(func(ctx *gin.Context) { ctx.Set(middleware.AccountKey, account) })
+	}
+	router.GET("/:code", handler.GetFullURL)
+	router.GET("/fetch/:code", handler.GetUrlMetadata)
+	router.GET("/preview/:code", handler.GetPreview)
+	return router
+}
+
+// get sends a GET request to router
+func get(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestPreviewPage checks that the "+" suffix, /preview/:code and links created with preview render the preview page
+func TestPreviewPage(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expire := created.Add(48 * time.Hour)
+	router := newTestRouter(map[string]models.Url{
+		"abc":    {URL: "http://192.0.2.10/setup.exe", ShortURL: "abc", CreatedAt: created, Expire: expire},
+		"forced": {URL: "https://example.com/landing-page", ShortURL: "forced", CreatedAt: created, Expire: created, Preview: true},
+		"plain":  {URL: "https://example.com/landing-page", ShortURL: "plain", CreatedAt: created, Expire: created},
+	}, nil, nil)
+
+	tests := []struct {
+		name     string
+		path     string
+		contains []string
+	}{
+		{"suffix", "/abc+", []string{"http://192.0.2.10/setup.exe", "2026-03-03 12:00 UTC", "raw IP address", "installer"}},
+		{"preview route", "/preview/abc", []string{"http://192.0.2.10/setup.exe", "2026-03-03 12:00 UTC", "does not use HTTPS"}},
+		{"forced preview", "/forced", []string{"https://example.com/landing-page", "Never"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(router, tt.path)
+			require.Equal(t, 200, rec.Code)
+			require.Empty(t, rec.Header().Get("Location"))
+			require.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+			require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			for _, text := range tt.contains {
+				require.Contains(t, rec.Body.String(), text)
+			}
+		})
+	}
+
+	// Links without preview redirect, and unknown codes are reported as missing on every route
+	rec := get(router, "/plain")
+	require.Equal(t, 302, rec.Code)
+	require.Equal(t, "https://example.com/landing-page", rec.Header().Get("Location"))
+	require.Equal(t, 404, get(router, "/missing+").Code)
+	require.Equal(t, 404, get(router, "/preview/missing").Code)
+}
+
+// TestFetchWorkspaceLink checks that the metadata of workspace links is only returned to members of the workspace
+func TestFetchWorkspaceLink(t *testing.T) {
+	created := time.Now()
+	workspaceId := 1
+	links := map[string]models.Url{
+		"team":   {URL: "https://example.com/team", ShortURL: "team", CreatedAt: created, Expire: created, WorkspaceId: &workspaceId},
+		"public": {URL: "https://example.com/public", ShortURL: "public", CreatedAt: created, Expire: created},
+	}
+	workspaces := services.NewWorkspaceService(&memoryWorkspaceRepo{
+		workspaces: map[int]models.Workspace{1: {Id: 1, Name: "Team"}},
+		members:    map[int][]models.Member{1: {{WorkspaceId: 1, AccountId: 1, Role: models.RoleViewer}}},
+	}, nil, time.Hour)
+
+	tests := []struct {
+		name    string
+		account *models.Account
+		path    string
+		code    int
+	}{
+		{"member", &models.Account{Id: 1}, "/fetch/team", 200},
+		{"admin", &models.Account{Id: 3, Admin: true}, "/fetch/team", 200},
+		{"non-member", &models.Account{Id: 2}, "/fetch/team", 404},
+		{"anonymous", nil, "/fetch/team", 401},
+		{"link without workspace", &models.Account{Id: 2}, "/fetch/public", 200},
+		{"anonymous link without workspace", nil, "/fetch/public", 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get(newTestRouter(links, workspaces, tt.account), tt.path)
+			require.Equal(t, tt.code, rec.Code)
+			if tt.code != 200 {
+				require.NotContains(t, rec.Body.String(), "example.com")
+			}
+		})
+	}
+}
+
+// TestShortUrlPrefix checks that custom domains take the scheme of SHORT_URL_PREFIX
+func TestShortUrlPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		domain string
+		want   string
+	}{
+		{"default domain", "http://localhost:3000/", "", "http://localhost:3000/"},
+		{"http", "http://localhost:3000/", "go.example.com", "http://go.example.com/"},
+		{"https", "https://sho.rt/", "go.example.com", "https://go.example.com/"},
+		{"no prefix", "", "go.example.com", "https://go.example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SHORT_URL_PREFIX", tt.prefix)
+			require.Equal(t, tt.want, shortUrlPrefix(tt.domain))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"strconv"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler handles HTTP requests about workspaces, their members, invitations, stats and quotas
+type WorkspaceHandler struct {
+	WorkspaceService *services.WorkspaceService // Service for workspace operations
+	QuotaService     *services.QuotaService     // Service for quota lookups
+}
+
+// CreateWorkspaceRequest represents the expected JSON payload for creating a workspace
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required"` // Display name
+}
+
+// SetRoleRequest represents the expected JSON payload for changing the role of a member
+type SetRoleRequest struct {
+	Role models.Role `json:"role" binding:"required"` // owner, admin, editor or viewer
+}
+
+// InviteRequest represents the expected JSON payload for inviting an account to a workspace
+type InviteRequest struct {
+	Email string      `json:"email" binding:"required,email"` // Email of the account allowed to accept the invitation
+	Role  models.Role `json:"role" binding:"required"`        // Role given on acceptance
+}
+
+// AcceptInvitationRequest represents the expected JSON payload for accepting an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"` // Token returned when the invitation was created
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler with the given services
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService, quotaService *services.QuotaService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		WorkspaceService: workspaceService,
+		QuotaService:     quotaService,
+	}
+}
+
+// CreateWorkspace handles POST /workspaces requests
+// Creates a workspace on the plan of the authenticated account, which becomes its owner
+func (w *WorkspaceHandler) CreateWorkspace(ctx *gin.Context) {
+	var req CreateWorkspaceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	membership, err := w.WorkspaceService.CreateWorkspace(ctx.Request.Context(), middleware.CurrentAccount(ctx), req.Name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(201, membership)
+}
+
+// ListWorkspaces handles GET /workspaces requests
+// Returns the workspaces of the authenticated account along with its role in each
+func (w *WorkspaceHandler) ListWorkspaces(ctx *gin.Context) {
+	memberships, err := w.WorkspaceService.ListWorkspaces(ctx.Request.Context(), middleware.CurrentAccount(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, memberships)
+}
+
+// GetWorkspace handles GET /workspaces/:id requests
+// Returns the workspace along with the role of the client in it
+func (w *WorkspaceHandler) GetWorkspace(ctx *gin.Context) {
+	ctx.JSON(200, models.Membership{Workspace: *middleware.CurrentWorkspace(ctx), Role: middleware.CurrentRole(ctx)})
+}
+
+// ListMembers handles GET /workspaces/:id/members requests
+// Returns the members of the workspace and their roles
+func (w *WorkspaceHandler) ListMembers(ctx *gin.Context) {
+	members, err := w.WorkspaceService.ListMembers(ctx.Request.Context(), middleware.CurrentWorkspace(ctx).Id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, members)
+}
+
+// SetMemberRole handles PUT /workspaces/:id/members/:account_id requests
+// Changes the role of a member; only owners may grant or take away the owner role
+func (w *WorkspaceHandler) SetMemberRole(ctx *gin.Context) {
+	var req SetRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+	accountId, err := strconv.Atoi(ctx.Param("account_id"))
+	if err != nil {
+		ctx.Error(utils.ErrMemberNotFound)
+		return
+	}
+
+	member, err := w.WorkspaceService.SetRole(ctx.Request.Context(), middleware.CurrentRole(ctx), middleware.CurrentWorkspace(ctx).Id, accountId, req.Role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, member)
+}
+
+// RemoveMember handles DELETE /workspaces/:id/members/:account_id requests
+// Removes a member from the workspace; only owners may remove owners
+func (w *WorkspaceHandler) RemoveMember(ctx *gin.Context) {
+	accountId, err := strconv.Atoi(ctx.Param("account_id"))
+	if err != nil {
+		ctx.Error(utils.ErrMemberNotFound)
+		return
+	}
+
+	if err := w.WorkspaceService.RemoveMember(ctx.Request.Context(), middleware.CurrentRole(ctx), middleware.CurrentWorkspace(ctx).Id, accountId); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// Invite handles POST /workspaces/:id/invitations requests
+// Returns the invitation along with its token, which is only shown once and must be passed on to the invitee
+func (w *WorkspaceHandler) Invite(ctx *gin.Context) {
+	var req InviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	invitation, token, err := w.WorkspaceService.Invite(ctx.Request.Context(), middleware.CurrentAccount(ctx), middleware.CurrentRole(ctx), middleware.CurrentWorkspace(ctx).Id, req.Email, req.Role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(201, gin.H{
+		"invitation": invitation,
+		"token":      token,
+	})
+}
+
+// AcceptInvitation handles POST /invitations/accept requests
+// Adds the authenticated account to the workspace of the invitation, whose email must match the account's
+func (w *WorkspaceHandler) AcceptInvitation(ctx *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(utils.ErrValidation)
+		return
+	}
+
+	membership, err := w.WorkspaceService.AcceptInvitation(ctx.Request.Context(), middleware.CurrentAccount(ctx), req.Token)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, membership)
+}
+
+// GetStats handles GET /workspaces/:id/stats requests
+// Returns the number of links of the workspace and their clicks, in total, by link, by country and by device class
+func (w *WorkspaceHandler) GetStats(ctx *gin.Context) {
+	stats, err := w.WorkspaceService.GetStats(ctx.Request.Context(), middleware.CurrentWorkspace(ctx).Id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
+
+// GetQuota handles GET /workspaces/:id/quota requests
+// Returns the plan and the daily and monthly quota shared by the links of the workspace
+func (w *WorkspaceHandler) GetQuota(ctx *gin.Context) {
+	usage, err := w.QuotaService.Usage(ctx.Request.Context(), middleware.CurrentAccount(ctx), middleware.CurrentWorkspace(ctx), ctx.ClientIP())
+	if err != nil {
+		ctx.Error(utils.ErrCacheUnavailable)
+		return
+	}
+
+	middleware.SetQuotaHeaders(ctx, usage)
+	ctx.JSON(200, usage)
+}
@@ -0,0 +1,130 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per Window, with bursts of up to Requests requests.
+// It is enforced as a token bucket holding Requests tokens and refilled continuously over Window.
+type Limit struct {
+	Requests int           // Bucket capacity (maximum burst)
+	Window   time.Duration // Time to refill an empty bucket
+}
+
+// String returns the limit in the configuration format, e.g. "5/1h0m0s".
+func (l Limit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Window.String()
+}
+
+// Result is the outcome of a rate limited request.
+type Result struct {
+	Allowed    bool          // Whether the request may proceed
+	Limit      int           // Bucket capacity
+	Remaining  int           // Requests left right now
+	Reset      time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next request is allowed (0 if allowed)
+}
+
+// Limiter takes a token from the bucket of a key.
+// Implementations must update the bucket atomically so concurrent requests never overdraw it.
+type Limiter interface {
+	// Allow takes a token for key under limit and reports whether the request may proceed.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of a token bucket.
+type bucket struct {
+	tokens float64   // Tokens left at updated
+	update time.Time // When tokens was last computed
+}
+
+// take refills the bucket up to now and takes a token if one is available.
+// RedisLimiter runs the same algorithm in Lua.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	rate := capacity / float64(limit.Window) // Tokens per nanosecond
+
+	elapsed := max(now.Sub(b.update), 0)
+	b.tokens = min(capacity, b.tokens+float64(elapsed)*rate)
+	b.update = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(limit, allowed, b.tokens)
+}
+
+// newResult describes a bucket of limit left with tokens after a request.
+func newResult(limit Limit, allowed bool, tokens float64) Result {
+	capacity := float64(limit.Requests)
+	rate := capacity / float64(limit.Window)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((capacity - tokens) / rate)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+	return result
+}
+
+// Rules holds the limits of every route for every plan.
+// The plan "*" applies to plans without a limit of their own.
+type Rules map[string]map[string]Limit
+
+// Lookup returns the limit of a route for a plan.
+// Returns false if the route is not limited for that plan.
+func (r Rules) Lookup(route string, plan string) (Limit, bool) {
+	plans, ok := r[route]
+	if !ok {
+		return Limit{}, false
+	}
+	if limit, ok := plans[plan]; ok {
+		return limit, true
+	}
+	limit, ok := plans["*"]
+	return limit, ok
+}
+
+// ParseRules parses rules written as comma-separated "route:plan=requests/window" entries,
+// e.g. "shorten:anonymous=5/1h,shorten:*=50/24h". A limit of 0 requests disables limiting.
+func ParseRules(value string) (Rules, error) {
+	rules := Rules{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		target, spec, ok := strings.Cut(entry, "=")
+		route, plan, ok2 := strings.Cut(target, ":")
+		requestsValue, windowValue, ok3 := strings.Cut(spec, "/")
+		if !ok || !ok2 || !ok3 || route == "" || plan == "" {
+			return nil, fmt.Errorf("invalid rate limit rule %q, expected route:plan=requests/window", entry)
+		}
+
+		requests, err := strconv.Atoi(requestsValue)
+		if err != nil || requests < 0 {
+			return nil, fmt.Errorf("invalid request count in rate limit rule %q", entry)
+		}
+		window, err := time.ParseDuration(windowValue)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid window in rate limit rule %q", entry)
+		}
+
+		if rules[route] == nil {
+			rules[route] = map[string]Limit{}
+		}
+		rules[route][plan] = Limit{Requests: requests, Window: window}
+	}
+	return rules, nil
+}
@@ -0,0 +1,79 @@
+package limiter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"urlshortener/limiter"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// testLimiter checks the token bucket behaviour shared by every Limiter
+func testLimiter(t *testing.T, l limiter.Limiter) {
+	ctx := context.Background()
+	limit := limiter.Limit{Requests: 3, Window: 300 * time.Millisecond}
+
+	for i := range 3 {
+		result, err := l.Allow(ctx, "client", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Limit)
+		require.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := l.Allow(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Greater(t, result.RetryAfter, time.Duration(0))
+	require.LessOrEqual(t, result.RetryAfter, 100*time.Millisecond)
+
+	// Other keys have their own bucket
+	result, err = l.Allow(ctx, "other", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// A token is refilled every 100ms
+	time.Sleep(120 * time.Millisecond)
+	result, err = l.Allow(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	testLimiter(t, limiter.NewMemoryLimiter())
+}
+
+func TestRedisLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	testLimiter(t, limiter.NewRedisLimiter(client, time.Second, nil))
+
+	// Buckets expire once they would be full again
+	require.Greater(t, server.TTL("rate:client"), time.Duration(0))
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := limiter.ParseRules("shorten:anonymous=5/1h, shorten:*=50/24h")
+	require.NoError(t, err)
+
+	limit, ok := rules.Lookup("shorten", "anonymous")
+	require.True(t, ok)
+	require.Equal(t, limiter.Limit{Requests: 5, Window: time.Hour}, limit)
+
+	limit, ok = rules.Lookup("shorten", "pro")
+	require.True(t, ok)
+	require.Equal(t, limiter.Limit{Requests: 50, Window: 24 * time.Hour}, limit)
+
+	_, ok = rules.Lookup("redirect", "anonymous")
+	require.False(t, ok)
+
+	for _, value := range []string{"shorten=5/1h", "shorten:anonymous=five/1h", "shorten:anonymous=5/forever"} {
+		_, err := limiter.ParseRules(value)
+		require.Error(t, err, value)
+	}
+}
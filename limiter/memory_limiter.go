@@ -0,0 +1,60 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the number of requests between two sweeps of full buckets.
+const sweepEvery = 1024
+
+// memoryBucket is a bucket along with the limit it was last used with.
+type memoryBucket struct {
+	bucket
+	limit Limit // Limit of the last request, used to tell when the bucket is full again
+}
+
+// MemoryLimiter implements Limiter with token buckets in process memory.
+// It suits the in-memory cache backend, where a single instance serves every request.
+// Buckets that refilled completely are dropped periodically, since a missing bucket is a full one.
+type MemoryLimiter struct {
+	mu       sync.Mutex               // Guards the fields below
+	buckets  map[string]*memoryBucket // Buckets by key
+	requests int                      // Requests since the last sweep
+}
+
+// NewMemoryLimiter creates a new empty MemoryLimiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*memoryBucket)}
+}
+
+// Allow takes a token for key under limit and reports whether the request may proceed.
+func (m *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.requests++
+	if m.requests >= sweepEvery {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: bucket{tokens: float64(limit.Requests), update: now}}
+		m.buckets[key] = b
+	}
+	b.limit = limit
+	return b.take(limit, now), nil
+}
+
+// sweep drops the buckets that are full again. Must be called with mu held.
+func (m *MemoryLimiter) sweep(now time.Time) {
+	m.requests = 0
+	for key, b := range m.buckets {
+		if now.Sub(b.update) >= b.limit.Window {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package limiter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes a token from the bucket stored in the hash KEYS[1].
+// ARGV: capacity, refill window (ms), current time (ms).
+// The key expires once the bucket would be full again, since a missing bucket is a full one.
+// Returns whether the request is allowed and the tokens left (as a string, Lua numbers are truncated).
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local rate = capacity / window
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((capacity - tokens) / rate)))
+return {allowed, tostring(tokens)}
+`)
+
+// Breaker guards calls to a backend that may be failing (e.g., cache.CircuitBreakerCache).
+type Breaker interface {
+	// Do runs call unless the backend is known to be down.
+	Do(ctx context.Context, call func(ctx context.Context) error) error
+}
+
+// RedisLimiter implements Limiter with token buckets stored in Redis, so every instance shares them.
+// Each request runs a single Lua script, which makes the refill and the take atomic.
+type RedisLimiter struct {
+	redis   *redis.Client // Redis client instance
+	timeout time.Duration // Maximum duration of a single Redis call (0 means no extra timeout)
+	breaker Breaker       // Fails fast while Redis is down (nil to always call Redis)
+}
+
+// NewRedisLimiter creates a new RedisLimiter with the given Redis client, per-call timeout and breaker.
+func NewRedisLimiter(redis *redis.Client, timeout time.Duration, breaker Breaker) *RedisLimiter {
+	return &RedisLimiter{
+		redis:   redis,
+		timeout: timeout,
+		breaker: breaker,
+	}
+}
+
+// Allow takes a token for key under limit and reports whether the request may proceed.
+// Buckets are stored under "rate:<key>".
+func (r *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	var result Result
+	call := func(ctx context.Context) error {
+		if r.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+		}
+		reply, err := tokenBucketScript.Run(ctx, r.redis, []string{"rate:" + key},
+			limit.Requests, limit.Window.Milliseconds(), time.Now().UnixMilli()).Slice()
+		if err != nil {
+			return err
+		}
+		tokens, err := strconv.ParseFloat(reply[1].(string), 64)
+		if err != nil {
+			return err
+		}
+		result = newResult(limit, reply[0].(int64) == 1, tokens)
+		return nil
+	}
+
+	if r.breaker == nil {
+		return result, call(ctx)
+	}
+	return result, r.breaker.Do(ctx, call)
+}
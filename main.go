@@ -2,22 +2,32 @@ package main
 
 import (
 	"context"
+	"expvar"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
-	"urlshortener/cache"
+	"urlshortener/abuse"
+	"urlshortener/autotls"
 	"urlshortener/db"
+	"urlshortener/geoip"
 	"urlshortener/handlers"
+	"urlshortener/limiter"
 	"urlshortener/middleware"
-	Redis "urlshortener/redis"
+	"urlshortener/openapi"
+	"urlshortener/pb"
+	"urlshortener/quota"
 	"urlshortener/repositories"
+	"urlshortener/rpc"
 	"urlshortener/services"
+	"urlshortener/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -37,44 +47,222 @@ func main() {
 	}
 	defer db.Close() // Ensure DB connection is closed on exit
 
-	// Initialize Redis client
-	redis, err := Redis.NewRedisClient(0)
-	if err != nil {
-		panic(err) // Panic if Redis connection fails
-	}
-	defer redis.Close() // Ensure Redis connection is closed on exit
+	// Initialize the cache backend selected by CACHE_BACKEND
+	backend := newCache(os.Getenv("CACHE_BACKEND"))
+	defer backend.close() // Ensure the cache connection is closed on exit
 
-	// Create Redis cache wrapper
-	redisCache := cache.NewRedisCache(redis)
+	// Context of background workers, cancelled on shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	// Set up repositories and services
 	mysqlUrlRepo := repositories.NewMysqlUrlRepository(db)
-	redisMysqlUrlRepo := repositories.NewRedisMysqlUrlRepository(mysqlUrlRepo, redisCache)
-	urlService := services.NewUrlService(redisMysqlUrlRepo)
-	urlHandler := handlers.NewShortenHandler(urlService)
+	repoOptions := []repositories.RedisMysqlUrlRepositoryOption{
+		repositories.WithEarlyRefresh(utils.GetEnvFloat("CACHE_EARLY_REFRESH_BETA", 1)),
+		repositories.WithNegativeCache(utils.GetEnvDuration("NEGATIVE_CACHE_TTL", 30*time.Second)),
+	}
+	if backend.breaker != nil && utils.GetEnvBool("CACHE_FILL_LOCK", false) {
+		// Only one instance repopulates a missing cache entry, the others wait for it
+		repoOptions = append(repoOptions, repositories.WithFillLock(backend.breaker, 2*time.Second, 250*time.Millisecond))
+	}
+	redisMysqlUrlRepo := repositories.NewRedisMysqlUrlRepository(mysqlUrlRepo, backend.cache, repoOptions...)
+
+	// Reject unknown short codes with a Bloom filter of every existing code, rebuilt from MySQL
+	bloomUrlRepo := repositories.NewBloomUrlRepository(
+		redisMysqlUrlRepo,
+		backend.newInvalidator("bloom:add"),
+		utils.GetEnvInt("BLOOM_EXPECTED_ITEMS", 1_000_000),
+		utils.GetEnvFloat("BLOOM_FALSE_POSITIVE_RATE", 0.01),
+	)
+	// The first build runs in the background so a large table can't delay startup; lookups pass through until it is ready
+	go func() {
+		bloomUrlRepo.Rebuild(workerCtx, mysqlUrlRepo)
+		bloomUrlRepo.RebuildEvery(workerCtx, mysqlUrlRepo, utils.GetEnvDuration("BLOOM_REBUILD_INTERVAL", 10*time.Minute))
+	}()
+	go bloomUrlRepo.Listen(workerCtx)
+	expvar.Publish("bloom_filter", expvar.Func(func() any { return bloomUrlRepo.Stats() }))
+
+	// Warm the cache with popular links before accepting traffic, within a time budget so startup can't stall
+	if count := utils.GetEnvInt("CACHE_WARMUP_COUNT", 1000); count > 0 {
+		warmCtx, cancelWarm := context.WithTimeout(workerCtx, utils.GetEnvDuration("CACHE_WARMUP_TIMEOUT", 5*time.Second))
+		start := time.Now()
+		warmed, err := redisMysqlUrlRepo.Warm(warmCtx, mysqlUrlRepo, count)
+		cancelWarm()
+		if err != nil {
+			slog.Warn(" [main.go] [CACHE WARMUP] ", slog.Int("warmed", warmed), slog.Any("error", err))
+		} else {
+			slog.Info(" [main.go] [CACHE WARMUP] ", slog.Int("warmed", warmed), slog.Duration("duration", time.Since(start)))
+		}
+	}
+
+	// Look up visitor countries in a local MaxMind-format database, reloaded when the file changes
+	var geo services.GeoLocator
+	if path := os.Getenv("GEOIP_DATABASE"); path != "" {
+		geoReader, err := geoip.Open(path)
+		if err != nil {
+			panic(err) // Panic on misconfiguration
+		}
+		defer geoReader.Close()
+		go geoReader.ReloadEvery(workerCtx, utils.GetEnvDuration("GEOIP_RELOAD_INTERVAL", time.Minute))
+		geo = geoReader
+	}
+
+	// Record clicks in the background, stored in batches
+	clickRepo := repositories.NewMysqlClickRepository(db)
+	clickService := services.NewClickService(clickRepo, utils.GetEnvInt("CLICK_BUFFER_SIZE", 10000))
+	go clickService.Run(utils.GetEnvDuration("CLICK_FLUSH_INTERVAL", time.Second))
+	expvar.Publish("clicks", expvar.Func(func() any { return clickService.Stats() }))
+
+	campaignService := services.NewCampaignService(repositories.NewMysqlCampaignRepository(db), clickRepo)
+	campaignHandler := handlers.NewCampaignHandler(campaignService)
+
+	// Serve links on the custom domains verified with a DNS TXT record
+	domainService := services.NewDomainService(repositories.NewMysqlDomainRepository(db), net.DefaultResolver)
+	domainService.Refresh(workerCtx) // On failure only the default domain is served until the next refresh
+	go domainService.RefreshEvery(workerCtx, utils.GetEnvDuration("DOMAIN_REFRESH_INTERVAL", time.Minute))
+	domainHandler := handlers.NewDomainHandler(domainService)
+
+	// Share links between the members of workspaces, with a role each
+	workspaceService := services.NewWorkspaceService(repositories.NewMysqlWorkspaceRepository(db), clickRepo, utils.GetEnvDuration("INVITATION_TTL", 7*24*time.Hour))
+
+	urlService := services.NewUrlService(bloomUrlRepo, geo)
+	urlHandler := handlers.NewShortenHandler(urlService, clickService, campaignService, domainService, workspaceService)
+	qrHandler := handlers.NewQrHandler(urlService, domainService, backend.cache)
+	appLinksHandler, err := handlers.NewAppLinksHandler(handlers.AppLinksConfig{
+		AppleAppIds:         utils.GetEnvList("APPLE_APP_IDS"),
+		AndroidPackage:      os.Getenv("ANDROID_APP_PACKAGE"),
+		AndroidFingerprints: utils.GetEnvList("ANDROID_APP_FINGERPRINTS"),
+	})
+	if err != nil {
+		panic(err) // Panic on misconfiguration
+	}
+
+	// Set up accounts and link quotas
+	quotaPlans, err := quota.ParsePlans(os.Getenv("QUOTA_PLANS"))
+	if err != nil {
+		panic(err) // Panic on misconfiguration
+	}
+	accountService := services.NewAccountService(repositories.NewMysqlAccountRepository(db), quotaPlans)
+	quotaService := services.NewQuotaService(backend.newQuotaCounter(), quotaPlans)
+	accountHandler := handlers.NewAccountHandler(accountService, quotaService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService, quotaService)
+	linkHandler := handlers.NewLinkHandler(services.NewSearchService(mysqlUrlRepo))
+	failurePolicy := middleware.ParseFailurePolicy(os.Getenv("RATE_LIMIT_FAILURE_POLICY"))
+
+	// Detect short code enumeration from the rate of lookups of unknown codes
+	abuseGuard := abuse.NewGuard(abuse.Config{
+		Window:      utils.GetEnvDuration("ABUSE_MISS_WINDOW", time.Minute),
+		BanAfter:    utils.GetEnvInt("ABUSE_BAN_AFTER", 100),
+		BanDuration: utils.GetEnvDuration("ABUSE_BAN_DURATION", 15*time.Minute),
+		DelayAfter:  utils.GetEnvInt("ABUSE_DELAY_AFTER", 20),
+		DelayStep:   utils.GetEnvDuration("ABUSE_DELAY_STEP", 50*time.Millisecond),
+		MaxDelay:    utils.GetEnvDuration("ABUSE_MAX_DELAY", 2*time.Second),
+	})
+	expvar.Publish("abuse", expvar.Func(func() any { return abuseGuard.Stats() }))
+	statusHandler := handlers.NewStatusHandler(db, backend.breaker)
+
+	// Parse the rate limits of every route and plan
+	rateLimitRules, err := limiter.ParseRules(utils.GetEnv("RATE_LIMITS", "shorten:anonymous=5/1h,shorten:*=50/24h"))
+	if err != nil {
+		panic(err) // Panic on misconfiguration
+	}
+
+	// Load the OpenAPI document used for request validation
+	spec, err := openapi.Load()
+	if err != nil {
+		panic(err) // Panic if the embedded OpenAPI document is invalid
+	}
 
 	// Set up Gin router and endpoints
 	router := gin.Default()
-	router.GET("/:code", urlHandler.GetFullURL)                                                           // Redirect to original URL
-	router.GET("/fetch/:code", urlHandler.GetUrlMetadata)                                            // Fetch original URL without redirect
-	router.POST("/shorten", middleware.RateLimitByUserAgentMiddleware(redisCache), urlHandler.ShortenURL) // Create a new short URL
+	// Only honour X-Forwarded-For from the configured proxies, so clients can't pick their own IP
+	if err := router.SetTrustedProxies(utils.GetEnvList("TRUSTED_PROXIES")); err != nil {
+		panic(err) // Panic on misconfiguration
+	}
+	if headers := utils.GetEnvList("CLIENT_IP_HEADERS"); len(headers) > 0 {
+		router.RemoteIPHeaders = headers // e.g., CF-Connecting-IP behind Cloudflare
+	}
+	router.Use(middleware.RequestIdMiddleware(), middleware.ErrorMiddleware())
+	router.Use(middleware.AuthMiddleware(accountService))
+	router.Use(middleware.OpenApiValidationMiddleware(spec))
+	registerRoutes(router, routeHandlers{
+		url:      urlHandler,
+		status:   statusHandler,
+		account:  accountHandler,
+		qr:       qrHandler,
+		campaign: campaignHandler,
+		appLinks: appLinksHandler,
+		domain:   domainHandler,
+		rateLimit: middleware.NewRateLimiter(
+			backend.newLimiter(),
+			rateLimitRules,
+			middleware.ParseKeyFunc(os.Getenv("RATE_LIMIT_KEY")),
+			failurePolicy,
+		),
+		quota: middleware.QuotaMiddleware(quotaService, failurePolicy),
+		abuse: middleware.AbuseGuardMiddleware(abuseGuard),
+
+		workspace:  workspaceHandler,
+		workspaces: workspaceService,
+		links:      linkHandler,
+	})
 
 	// Build server address from environment variables
 	host := os.Getenv("SERVER_HOST")
 	port := os.Getenv("PORT")
 	addr := net.JoinHostPort(host, port)
 
+	// Base context of every HTTP request, cancelled if requests outlive the shutdown timeout
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
 	// Create HTTP server
 	server := &http.Server{
-		Addr:    addr,
-		Handler: router,
+		Addr:        addr,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	// Optionally serve HTTPS with ACME certificates instead, and redirect plain HTTP to it
+	var redirectServer *http.Server
+	if utils.GetEnvBool("ACME_ENABLED", false) {
+		certManager := newCertManager(db, domainService)
+		httpsPort := utils.GetEnv("HTTPS_PORT", "443")
+		server.Addr = net.JoinHostPort(host, httpsPort)
+		server.TLSConfig = certManager.TLSConfig()
+		redirectServer = &http.Server{
+			Addr:    addr,
+			Handler: certManager.HTTPHandler(autotls.RedirectHandler(httpsPort)), // Also answers HTTP-01 challenges
+		}
+	}
+
+	// Create gRPC server sharing the same UrlService
+	grpcServer := grpc.NewServer()
+	pb.RegisterUrlShortenerServer(grpcServer, rpc.NewUrlServer(urlService))
+
+	// Listen for gRPC on its own port
+	grpcListener, err := net.Listen("tcp", net.JoinHostPort(host, utils.GetEnv("GRPC_PORT", "3001")))
+	if err != nil {
+		panic(err) // Panic if the gRPC port cannot be bound
 	}
 
 	// Channel to receive server errors
-	serverErrorCh := make(chan error, 1)
+	serverErrorCh := make(chan error, 3)
 	go func() {
+		if server.TLSConfig != nil {
+			serverErrorCh <- server.ListenAndServeTLS("", "") // Start HTTPS server, certificates come from TLSConfig
+			return
+		}
 		serverErrorCh <- server.ListenAndServe() // Start HTTP server
 	}()
+	if redirectServer != nil {
+		go func() {
+			serverErrorCh <- redirectServer.ListenAndServe() // Start HTTP to HTTPS redirects
+		}()
+	}
+	go func() {
+		serverErrorCh <- grpcServer.Serve(grpcListener) // Start gRPC server
+	}()
 
 	// Channel to listen for OS interrupt or terminate signals
 	exitCh := make(chan os.Signal, 1)
@@ -92,5 +280,23 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Stop the gRPC server gracefully, forcing it closed if the timeout expires first
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	if redirectServer != nil {
+		redirectServer.Shutdown(ctx)
+	}
 	server.Shutdown(ctx)
+	cancelBase()            // Cancel in-flight Redis and MySQL calls of requests that did not finish in time
+	clickService.Close(ctx) // Store the clicks still buffered
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"urlshortener/abuse"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbuseGuardMiddleware is a Gin middleware that protects short code lookups against enumeration.
+// Banned clients are rejected with HTTP 429 (Too Many Requests) and a Retry-After header before any lookup.
+// Lookups of unknown codes are recorded and their responses delayed once a client misses too often;
+// responses for existing codes are never delayed.
+//
+// Usage:
+//
+//	router.GET("/:code", AbuseGuardMiddleware(guard), handler)
+func AbuseGuardMiddleware(guard *abuse.Guard) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ip := ctx.ClientIP()
+		if banned, until := guard.Banned(ip, time.Now()); banned {
+			ctx.Header("Retry-After", strconv.Itoa(max(seconds(time.Until(until)), 1)))
+			ctx.Error(utils.ErrClientBanned)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || !errors.Is(ctx.Errors.Last().Err, utils.ErrUrlNotFound) {
+			return
+		}
+		delay, _ := guard.RecordMiss(ip, ctx.Request.URL.Path, time.Now())
+		if delay == 0 {
+			return
+		}
+		select {
+		case <-ctx.Request.Context().Done():
+		case <-time.After(delay):
+		}
+	}
+}
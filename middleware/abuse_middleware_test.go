@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"urlshortener/abuse"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAbuseGuardMiddleware checks that only lookups of unknown codes count as misses
+// and that banned clients are rejected with a Retry-After header
+func TestAbuseGuardMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	guard := abuse.NewGuard(abuse.Config{
+		Window:      time.Minute,
+		BanAfter:    2,
+		BanDuration: 10 * time.Minute,
+		DelayAfter:  10,
+		DelayStep:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	})
+	router := gin.New()
+	router.Use(ErrorMiddleware())
+	router.GET("/:code", AbuseGuardMiddleware(guard), func(ctx *gin.Context) {
+		switch ctx.Param("code") {
+		case "found":
+			ctx.Status(302)
+		case "expired":
+			ctx.Error(utils.ErrShortCodeExpired)
+		default:
+			ctx.Error(utils.ErrUrlNotFound)
+		}
+	})
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Hits and other errors are not misses
+	for range 3 {
+		require.Equal(t, 302, send("/found").Code)
+		require.Equal(t, 410, send("/expired").Code)
+	}
+	require.Zero(t, guard.Stats().Tracked)
+
+	require.Equal(t, 404, send("/missing1").Code)
+	require.Equal(t, 404, send("/missing2").Code)
+	require.Equal(t, 1, guard.Stats().Banned)
+
+	// Banned clients are rejected before the lookup, even for existing codes
+	rec := send("/found")
+	require.Equal(t, 429, rec.Code)
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.InDelta(t, 600, retryAfter, 1)
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApiKeyHeader is the header carrying the API key, as an alternative to "Authorization: Bearer <key>"
+const ApiKeyHeader = "X-Api-Key"
+
+// AccountKey is the Gin context key holding the *models.Account of an authenticated client
+const AccountKey = "account"
+
+// Authenticator resolves API keys to accounts (e.g., services.AccountService)
+type Authenticator interface {
+	// Authenticate returns the account owning apiKey and the id of the key,
+	// or utils.ErrUnauthorized if the key is unknown.
+	Authenticate(ctx context.Context, apiKey string) (*models.Account, int, error)
+}
+
+// AuthMiddleware is a Gin middleware that authenticates clients sending an API key
+// in the Authorization header ("Bearer <key>") or the X-Api-Key header.
+// Clients without a key stay anonymous; an unknown key is rejected with HTTP 401 (Unauthorized).
+// The account is stored under AccountKey, and its id, key id and plan under UserIdKey, ApiKeyIdKey and PlanKey.
+//
+// Usage:
+//
+//	router.Use(AuthMiddleware(accountService))
+func AuthMiddleware(auth Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey := ctx.GetHeader(ApiKeyHeader)
+		if bearer, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer "); ok {
+			apiKey = strings.TrimSpace(bearer)
+		}
+		if apiKey == "" {
+			ctx.Next() // Anonymous client
+			return
+		}
+
+		account, keyId, err := auth.Authenticate(ctx.Request.Context(), apiKey)
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(AccountKey, account)
+		ctx.Set(UserIdKey, strconv.Itoa(account.Id))
+		ctx.Set(ApiKeyIdKey, strconv.Itoa(keyId))
+		ctx.Set(PlanKey, account.Plan)
+		ctx.Next()
+	}
+}
+
+// CurrentAccount returns the account of the authenticated client, or nil for anonymous clients
+func CurrentAccount(ctx *gin.Context) *models.Account {
+	account, _ := ctx.Value(AccountKey).(*models.Account)
+	return account
+}
+
+// RequireAccount is a Gin middleware that rejects anonymous clients with HTTP 401 (Unauthorized)
+func RequireAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if CurrentAccount(ctx) == nil {
+			ctx.Error(utils.ErrUnauthorized)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireAdmin is a Gin middleware that only lets admin accounts through,
+// rejecting anonymous clients with HTTP 401 (Unauthorized) and other accounts with HTTP 403 (Forbidden)
+func RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		account := CurrentAccount(ctx)
+		switch {
+		case account == nil:
+			ctx.Error(utils.ErrUnauthorized)
+		case !account.Admin:
+			ctx.Error(utils.ErrForbidden)
+		default:
+			ctx.Next()
+			return
+		}
+		ctx.Abort()
+	}
+}
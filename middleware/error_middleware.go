@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 problem details body returned for every API error
+type Problem struct {
+	Type      string             `json:"type"`             // Problem type URI ("about:blank", the code identifies the problem)
+	Title     string             `json:"title"`            // Short summary of the HTTP status
+	Status    int                `json:"status"`           // HTTP status code
+	Detail    string             `json:"detail"`           // Human-readable explanation
+	Instance  string             `json:"instance"`         // Path of the request that failed
+	Code      string             `json:"code"`             // Stable machine-readable error code (e.g., link_expired)
+	RequestId string             `json:"request_id"`       // Id of the request that failed
+	Errors    []utils.FieldError `json:"errors,omitempty"` // Field-level details for validation errors
+}
+
+// ErrorMiddleware is a Gin middleware that renders errors attached with ctx.Error
+// as RFC 7807 problem+json responses.
+// Handlers report a failure by calling ctx.Error(err) and returning; the last error wins.
+// Errors are converted with utils.ToAppError, so sentinel errors from utils map to their status and code.
+//
+// Usage:
+//
+//	router.Use(RequestIdMiddleware(), ErrorMiddleware())
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		appErr := utils.ToAppError(ctx.Errors.Last().Err)
+		appErr.RequestId = ctx.GetString(RequestIdKey)
+
+		if appErr.Status >= http.StatusInternalServerError {
+			slog.Error(" [error_middleware.go] [REQUEST FAILED] ",
+				slog.String("request_id", appErr.RequestId),
+				slog.String("path", ctx.Request.URL.Path),
+				slog.Any("error", ctx.Errors.Last().Err),
+			)
+		}
+
+		ctx.Header("Content-Type", ProblemContentType)
+		ctx.JSON(appErr.Status, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(appErr.Status),
+			Status:    appErr.Status,
+			Detail:    appErr.Message,
+			Instance:  ctx.Request.URL.Path,
+			Code:      appErr.Code,
+			RequestId: appErr.RequestId,
+			Errors:    appErr.Fields,
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// declaredSentinels returns the names of the exported Err* variables declared in utils/errors.go
+func declaredSentinels(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "../utils/errors.go", nil, 0)
+	require.NoError(t, err)
+	var names []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.ValueSpec); ok {
+			for _, name := range spec.Names {
+				if name.IsExported() && strings.HasPrefix(name.Name, "Err") {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// TestErrorMiddleware checks that every sentinel error of utils renders as problem+json
+// with its stable code and status and the id of the request
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"ErrUrlNotFound", utils.ErrUrlNotFound, http.StatusNotFound, "link_not_found"},
+		{"ErrUrlAlreadyExists", utils.ErrUrlAlreadyExists, http.StatusConflict, "link_already_exists"},
+		{"ErrInvalidUrl", utils.ErrInvalidUrl, http.StatusBadRequest, "invalid_url"},
+		{"ErrUrlTooShort", utils.ErrUrlTooShort, http.StatusBadRequest, "url_too_short"},
+		{"ErrShortCodeExpired", utils.ErrShortCodeExpired, http.StatusGone, "link_expired"},
+		{"ErrShortCodeCollision", utils.ErrShortCodeCollision, http.StatusConflict, "short_code_collision"},
+		{"ErrDatabaseConnection", utils.ErrDatabaseConnection, http.StatusServiceUnavailable, "service_unavailable"},
+		{"ErrDatabaseQuery", utils.ErrDatabaseQuery, http.StatusInternalServerError, "internal_error"},
+		{"ErrDatabaseInsert", utils.ErrDatabaseInsert, http.StatusInternalServerError, "internal_error"},
+		{"ErrDatabaseUpdate", utils.ErrDatabaseUpdate, http.StatusInternalServerError, "internal_error"},
+		{"ErrDatabaseDelete", utils.ErrDatabaseDelete, http.StatusInternalServerError, "internal_error"},
+		{"ErrShortCodeRequired", utils.ErrShortCodeRequired, http.StatusBadRequest, "short_code_required"},
+		{"ErrValidation", utils.ErrValidation, http.StatusUnprocessableEntity, "validation_error"},
+		{"ErrRateLimitExceeded", utils.ErrRateLimitExceeded, http.StatusTooManyRequests, "rate_limit_exceeded"},
+		{"ErrCacheUnavailable", utils.ErrCacheUnavailable, http.StatusServiceUnavailable, "service_unavailable"},
+		{"ErrUnauthorized", utils.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{"ErrForbidden", utils.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"ErrQuotaExceeded", utils.ErrQuotaExceeded, http.StatusTooManyRequests, "quota_exceeded"},
+		{"ErrAccountNotFound", utils.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+		{"ErrAccountAlreadyExists", utils.ErrAccountAlreadyExists, http.StatusConflict, "account_already_exists"},
+		{"ErrUnknownPlan", utils.ErrUnknownPlan, http.StatusBadRequest, "unknown_plan"},
+		{"ErrClientBanned", utils.ErrClientBanned, http.StatusTooManyRequests, "client_banned"},
+		{"ErrInvalidRoutingRule", utils.ErrInvalidRoutingRule, http.StatusBadRequest, "invalid_routing_rule"},
+		{"ErrInvalidCountryTarget", utils.ErrInvalidCountryTarget, http.StatusBadRequest, "invalid_country_target"},
+		{"ErrInvalidSplit", utils.ErrInvalidSplit, http.StatusBadRequest, "invalid_split"},
+		{"ErrCampaignNotFound", utils.ErrCampaignNotFound, http.StatusNotFound, "campaign_not_found"},
+		{"ErrCampaignAlreadyExists", utils.ErrCampaignAlreadyExists, http.StatusConflict, "campaign_already_exists"},
+		{"ErrInvalidDomain", utils.ErrInvalidDomain, http.StatusBadRequest, "invalid_domain"},
+		{"ErrDomainNotFound", utils.ErrDomainNotFound, http.StatusNotFound, "domain_not_found"},
+		{"ErrDomainAlreadyExists", utils.ErrDomainAlreadyExists, http.StatusConflict, "domain_already_exists"},
+		{"ErrDomainNotVerified", utils.ErrDomainNotVerified, http.StatusConflict, "domain_not_verified"},
+		{"ErrInvalidDeepLink", utils.ErrInvalidDeepLink, http.StatusBadRequest, "invalid_deep_link"},
+		{"ErrWorkspaceNotFound", utils.ErrWorkspaceNotFound, http.StatusNotFound, "workspace_not_found"},
+		{"ErrInvalidRole", utils.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+		{"ErrMemberNotFound", utils.ErrMemberNotFound, http.StatusNotFound, "member_not_found"},
+		{"ErrMemberAlreadyExists", utils.ErrMemberAlreadyExists, http.StatusConflict, "member_already_exists"},
+		{"ErrLastOwner", utils.ErrLastOwner, http.StatusConflict, "last_owner"},
+		{"ErrInvitationNotFound", utils.ErrInvitationNotFound, http.StatusNotFound, "invitation_not_found"},
+		{"ErrInvalidTag", utils.ErrInvalidTag, http.StatusBadRequest, "invalid_tag"},
+	}
+
+	// Every sentinel must be listed above, so new ones get a stable code before they ship
+	listed := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		listed = append(listed, tt.name)
+	}
+	require.ElementsMatch(t, declaredSentinels(t), listed)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestIdMiddleware(), ErrorMiddleware())
+			router.GET("/fail", func(ctx *gin.Context) { ctx.Error(tt.err) })
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+			require.Equal(t, tt.status, rec.Code)
+			require.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			require.Equal(t, tt.status, problem.Status)
+			require.Equal(t, tt.code, problem.Code)
+			require.Equal(t, "/fail", problem.Instance)
+			require.NotEmpty(t, problem.RequestId)
+			require.Equal(t, rec.Header().Get(RequestIdHeader), problem.RequestId)
+		})
+	}
+}
+
+// TestToAppErrorWrapped checks that wrapped sentinels keep their code and unknown errors become internal_error
+func TestToAppErrorWrapped(t *testing.T) {
+	appErr := utils.ToAppError(errors.Join(utils.ErrUrlNotFound, utils.ErrDatabaseQuery))
+	require.Equal(t, "link_not_found", appErr.Code) // The first sentinel listed wins, every time
+	require.ErrorIs(t, appErr, utils.ErrDatabaseQuery)
+
+	appErr = utils.ToAppError(fmt.Errorf("resolve: %w", utils.ErrShortCodeExpired))
+	require.Equal(t, http.StatusGone, appErr.Status)
+
+	appErr = utils.ToAppError(errors.New("boom"))
+	require.Equal(t, http.StatusInternalServerError, appErr.Status)
+	require.Equal(t, "internal_error", appErr.Code)
+}
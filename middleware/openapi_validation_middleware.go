@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"urlshortener/openapi"
+	"urlshortener/utils"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenApiValidationMiddleware is a Gin middleware that validates incoming requests
+// (path parameters, query parameters and JSON bodies) against the OpenAPI document.
+// Requests that do not match fail with utils.ErrValidation (HTTP 422) carrying field-level errors.
+// Routes missing from the document are passed through unvalidated.
+//
+// Usage:
+//
+//	router.Use(OpenApiValidationMiddleware(spec))
+//
+// Arguments:
+//
+//	spec *openapi3.T: The parsed OpenAPI document (see openapi.Load).
+func OpenApiValidationMiddleware(spec *openapi3.T) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		MultiError:         true,                                  // Report every invalid field, not just the first
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, // Authentication is handled by other middleware
+	}
+
+	return func(ctx *gin.Context) {
+		// Find the operation matching the Gin route
+		path := openapi.PathFromGin(ctx.FullPath())
+		pathItem := spec.Paths.Value(path)
+		if pathItem == nil {
+			ctx.Next()
+			return
+		}
+		operation := pathItem.GetOperation(ctx.Request.Method)
+		if operation == nil {
+			ctx.Next()
+			return
+		}
+
+		// Collect path parameters from Gin
+		pathParams := make(map[string]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			pathParams[param.Key] = param.Value
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    ctx.Request,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      spec,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    ctx.Request.Method,
+				Operation: operation,
+			},
+			Options: options,
+		}
+
+		if err := openapi3filter.ValidateRequest(ctx.Request.Context(), input); err != nil {
+			appErr := utils.ToAppError(utils.ErrValidation)
+			appErr.Fields = fieldErrors(err)
+			ctx.Error(appErr)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// fieldErrors flattens a validation error returned by openapi3filter into field-level errors
+func fieldErrors(err error) []utils.FieldError {
+	var fields []utils.FieldError
+
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		for _, e := range multi {
+			fields = append(fields, fieldErrors(e)...)
+		}
+		return fields
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		// Body errors wrap schema errors, possibly several of them
+		if requestErr.Err != nil {
+			var nestedMulti openapi3.MultiError
+			var schemaErr *openapi3.SchemaError
+			if errors.As(requestErr.Err, &nestedMulti) || errors.As(requestErr.Err, &schemaErr) {
+				fields = fieldErrors(requestErr.Err)
+				// Prefix parameter errors with the parameter name
+				if requestErr.Parameter != nil {
+					for i := range fields {
+						fields[i].Field = strings.TrimSuffix(requestErr.Parameter.Name+"."+fields[i].Field, ".")
+					}
+				}
+				return fields
+			}
+		}
+		field := "body"
+		if requestErr.Parameter != nil {
+			field = requestErr.Parameter.Name
+		}
+		return []utils.FieldError{{Field: field, Message: requestErr.Error()}}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		field := strings.Join(schemaErr.JSONPointer(), ".")
+		return []utils.FieldError{{Field: field, Message: schemaErr.Reason}}
+	}
+
+	return []utils.FieldError{{Field: "body", Message: err.Error()}}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"urlshortener/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenApiValidationMiddleware checks that invalid bodies are rejected with field-level errors
+func TestOpenApiValidationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware(), OpenApiValidationMiddleware(spec))
+	router.POST("/shorten", func(ctx *gin.Context) { ctx.Status(201) })
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []string
+	}{
+		{"valid", `{"url": "https://example.com/some/long/path", "expire_in": 60}`, 201, nil},
+		{"missing url", `{"expire_in": 60}`, 422, []string{"url"}},
+		{"wrong types", `{"url": 42, "expire_in": "soon"}`, 422, []string{"url", "expire_in"}},
+		{"negative expiration", `{"url": "https://example.com/some/long/path", "expire_in": -1}`, 422, []string{"expire_in"}},
+		{"routing rules", `{"url": "https://example.com/some/long/path", "rules": [{"os": "ios", "url": "https://apps.apple.com/app/id000000000"}]}`, 201, nil},
+		{"unknown os", `{"url": "https://example.com/some/long/path", "rules": [{"os": "symbian", "url": "https://example.com/symbian"}]}`, 422, []string{"rules.0.os"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.status, rec.Code, rec.Body.String())
+			if tt.fields == nil {
+				return
+			}
+
+			var resp Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Equal(t, "validation_error", resp.Code)
+			var got []string
+			for _, field := range resp.Errors {
+				got = append(got, field.Field)
+			}
+			require.ElementsMatch(t, tt.fields, got, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaEnforcer counts the links created by every client (e.g., services.QuotaService)
+type QuotaEnforcer interface {
+	// Consume counts a link created at now, or returns utils.ErrQuotaExceeded along with the usage.
+	// Links created in a workspace (non-nil workspace) are counted against it and against the account.
+	Consume(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string, now time.Time) (quota.Usage, error)
+	// Refund takes back a link counted at now.
+	Refund(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string, now time.Time) error
+}
+
+// QuotaMiddleware is a Gin middleware that counts every request against the daily and monthly
+// link quota of the client, authenticated by AuthMiddleware or anonymous (counted by IP address).
+// Behind RequireWorkspaceRole, requests are counted against the quota of the workspace as well.
+// The remaining quota is returned in the X-Quota-Daily-* and X-Quota-Monthly-* headers;
+// clients without quota left get HTTP 429 (Too Many Requests) with a Retry-After header.
+// Requests that fail are not counted. If the counters are unavailable the request is allowed
+// or rejected according to policy.
+//
+// Usage:
+//
+//	router.POST("/shorten", QuotaMiddleware(quotaService, FailOpen), handler)
+func QuotaMiddleware(quotas QuotaEnforcer, policy FailurePolicy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		account, workspace, now := CurrentAccount(ctx), CurrentWorkspace(ctx), time.Now()
+
+		usage, err := quotas.Consume(ctx.Request.Context(), account, workspace, ctx.ClientIP(), now)
+		switch {
+		case errors.Is(err, utils.ErrQuotaExceeded):
+			SetQuotaHeaders(ctx, usage)
+			_, until := usage.Exhausted()
+			ctx.Header("Retry-After", strconv.Itoa(max(seconds(time.Until(until)), 1)))
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		case err != nil:
+			if policy == FailOpen {
+				ctx.Next() // Let the request through uncounted while the counters are down
+				return
+			}
+			ctx.Error(utils.ErrCacheUnavailable)
+			ctx.Abort()
+			return
+		}
+
+		SetQuotaHeaders(ctx, usage)
+		ctx.Next()
+
+		if len(ctx.Errors) > 0 {
+			// Nothing was created, give the link back
+			quotas.Refund(context.WithoutCancel(ctx.Request.Context()), account, workspace, ctx.ClientIP(), now)
+		}
+	}
+}
+
+// SetQuotaHeaders sets the X-Quota-Daily-* and X-Quota-Monthly-* headers describing usage
+// Unlimited periods only report how many links were created
+func SetQuotaHeaders(ctx *gin.Context, usage quota.Usage) {
+	ctx.Header("X-Quota-Plan", usage.Plan)
+	setPeriodHeaders(ctx, "X-Quota-Daily-", usage.Daily)
+	setPeriodHeaders(ctx, "X-Quota-Monthly-", usage.Monthly)
+}
+
+// setPeriodHeaders sets the headers describing the quota of a single period
+func setPeriodHeaders(ctx *gin.Context, prefix string, period quota.Period) {
+	ctx.Header(prefix+"Used", strconv.Itoa(period.Used))
+	if period.Limit == nil {
+		return
+	}
+	ctx.Header(prefix+"Limit", strconv.Itoa(*period.Limit))
+	ctx.Header(prefix+"Remaining", strconv.Itoa(*period.Remaining))
+	ctx.Header(prefix+"Reset", strconv.Itoa(seconds(time.Until(period.ResetAt))))
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthenticator knows a single API key of a free account limited to 1 link per day
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(ctx context.Context, apiKey string) (*models.Account, int, error) {
+	if apiKey != "good-key" {
+		return nil, 0, utils.ErrUnauthorized
+	}
+	daily := 1
+	return &models.Account{Id: 7, Plan: quota.PlanFree, DailyQuota: &daily}, 1, nil
+}
+
+// TestQuotaMiddleware checks that accounts and anonymous clients have separate quotas
+// and that failed requests are not counted
+func TestQuotaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	plans := quota.Plans{quota.PlanAnonymous: {Daily: 2, Monthly: 10}, quota.PlanFree: {Daily: 5, Monthly: 10}}
+	quotas := services.NewQuotaService(quota.NewMemoryCounter(), plans)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware(), AuthMiddleware(fakeAuthenticator{}))
+	router.POST("/shorten", QuotaMiddleware(quotas, FailOpen), func(ctx *gin.Context) {
+		if ctx.Query("fail") != "" {
+			ctx.Error(utils.ErrDatabaseInsert)
+			return
+		}
+		ctx.Status(201)
+	})
+
+	send := func(apiKey string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/shorten"+query, nil)
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, 401, send("bad-key", "").Code)
+
+	// Failed requests are refunded
+	require.Equal(t, 500, send("", "?fail=1").Code)
+	rec := send("", "")
+	require.Equal(t, 201, rec.Code)
+	require.Equal(t, "anonymous", rec.Header().Get("X-Quota-Plan"))
+	require.Equal(t, "1", rec.Header().Get("X-Quota-Daily-Remaining"))
+	require.Equal(t, "9", rec.Header().Get("X-Quota-Monthly-Remaining"))
+	require.Equal(t, 201, send("", "").Code)
+
+	rec = send("", "")
+	require.Equal(t, 429, rec.Code)
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// The account override applies instead of the free plan's daily quota
+	rec = send("good-key", "")
+	require.Equal(t, 201, rec.Code)
+	require.Equal(t, "free", rec.Header().Get("X-Quota-Plan"))
+	require.Equal(t, "0", rec.Header().Get("X-Quota-Daily-Remaining"))
+	require.Equal(t, 429, send("good-key", "").Code)
+}
@@ -1,60 +1,155 @@
 package middleware
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
+	"log/slog"
+	"math"
+	"strconv"
 	"time"
-	"urlshortener/cache"
+	"urlshortener/limiter"
+	"urlshortener/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimitByUserAgentMiddleware is a Gin middleware that limits the number of requests
-// from the same User-Agent within a specified time window using Redis as a backend.
-// If a User-Agent exceeds the allowed number of requests, it returns HTTP 429 (Too Many Requests).
+// UserIdKey is the Gin context key holding the id of the authenticated user (unset for anonymous clients)
+const UserIdKey = "user_id"
+
+// ApiKeyIdKey is the Gin context key holding the id of the API key the client authenticated with
+const ApiKeyIdKey = "api_key_id"
+
+// PlanKey is the Gin context key holding the plan of the client (AnonymousPlan if unset)
+const PlanKey = "plan"
+
+// AnonymousPlan is the plan of clients that did not authenticate
+const AnonymousPlan = "anonymous"
+
+// FailurePolicy decides how rate limiting behaves when the cache backing it is unavailable
+type FailurePolicy string
+
+const (
+	FailOpen   FailurePolicy = "open"   // Allow requests without limiting
+	FailClosed FailurePolicy = "closed" // Reject requests with HTTP 503 (Service Unavailable)
+)
+
+// ParseFailurePolicy converts a configuration value ("open" or "closed") to a FailurePolicy
+// Unknown values fall back to FailOpen
+func ParseFailurePolicy(value string) FailurePolicy {
+	if FailurePolicy(value) == FailClosed {
+		return FailClosed
+	}
+	if value != "" && FailurePolicy(value) != FailOpen {
+		slog.Warn(" [rate_limit_middleware.go] [UNKNOWN FAILURE POLICY] ", slog.String("policy", value))
+	}
+	return FailOpen
+}
+
+// KeyFunc returns the key identifying the client of a request for rate limiting
+type KeyFunc func(ctx *gin.Context) string
+
+// KeyByIp identifies clients by IP address
+// Forwarding headers are only honoured from the proxies trusted by the router (see gin.Engine.SetTrustedProxies)
+func KeyByIp(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// KeyByUser identifies authenticated clients by user id and anonymous clients by IP address
+func KeyByUser(ctx *gin.Context) string {
+	if userId := ctx.GetString(UserIdKey); userId != "" {
+		return "user:" + userId
+	}
+	return KeyByIp(ctx)
+}
+
+// KeyByApiKey identifies authenticated clients by API key and anonymous clients by IP address
+func KeyByApiKey(ctx *gin.Context) string {
+	if apiKeyId := ctx.GetString(ApiKeyIdKey); apiKeyId != "" {
+		return "api_key:" + apiKeyId
+	}
+	return KeyByIp(ctx)
+}
+
+// ParseKeyFunc converts a configuration value ("ip", "user" or "api_key") to a KeyFunc
+// Unknown values fall back to KeyByIp
+func ParseKeyFunc(value string) KeyFunc {
+	switch value {
+	case "user":
+		return KeyByUser
+	case "api_key":
+		return KeyByApiKey
+	case "", "ip":
+		return KeyByIp
+	default:
+		slog.Warn(" [rate_limit_middleware.go] [UNKNOWN RATE LIMIT KEY] ", slog.String("key", value))
+		return KeyByIp
+	}
+}
+
+// RateLimiter builds the rate limiting middleware of every route
+type RateLimiter struct {
+	limiter limiter.Limiter // Token buckets (e.g., limiter.RedisLimiter)
+	rules   limiter.Rules   // Limits by route and plan
+	key     KeyFunc         // Identifies the client of a request
+	policy  FailurePolicy   // What to do when the limiter cannot be reached
+}
+
+// NewRateLimiter creates a new RateLimiter enforcing rules with l, identifying clients with key
+func NewRateLimiter(l limiter.Limiter, rules limiter.Rules, key KeyFunc, policy FailurePolicy) *RateLimiter {
+	return &RateLimiter{
+		limiter: l,
+		rules:   rules,
+		key:     key,
+		policy:  policy,
+	}
+}
+
+// Route returns a Gin middleware that enforces the limits of route for the plan of each client.
+// Responses carry the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers;
+// requests over the limit get HTTP 429 (Too Many Requests) with a Retry-After header.
+// If the limiter is unavailable the request is allowed or rejected according to the failure policy.
 //
 // Usage:
 //
-//	router.Use(RateLimitByUserAgentMiddleware(redisCache))
-//
-// Arguments:
-//
-//	redis cache.Cache: The cache implementation (e.g., Redis) used for rate limiting.
-//
-// Rate limit: 5 requests per User-Agent per hour.
-func RateLimitByUserAgentMiddleware(redis cache.Cache) gin.HandlerFunc {
+//	router.POST("/shorten", rateLimiter.Route("shorten"), handler)
+func (r *RateLimiter) Route(route string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		userAgent := ctx.Request.UserAgent() // Get the User-Agent string from the request
-
-		// Hash the User-Agent to create a consistent and safe Redis key
-		hash := sha1.New()
-		hash.Write([]byte(userAgent))
-		key := "rate:user_agent:" + hex.EncodeToString(hash.Sum(nil))
+		plan := ctx.GetString(PlanKey)
+		if plan == "" {
+			plan = AnonymousPlan
+		}
+		limit, ok := r.rules.Lookup(route, plan)
+		if !ok || limit.Requests == 0 {
+			ctx.Next() // Route not limited for this plan
+			return
+		}
 
-		// Increment the request count for this User-Agent in Redis
-		count, err := redis.Incr(key)
+		result, err := r.limiter.Allow(ctx.Request.Context(), route+":"+r.key(ctx), limit)
 		if err != nil {
-			ctx.JSON(500, gin.H{
-				"error": "Internal server error",
-			})
+			if r.policy == FailOpen {
+				ctx.Next() // Let the request through unlimited while the limiter is down
+				return
+			}
+			ctx.Error(utils.ErrCacheUnavailable)
 			ctx.Abort()
 			return
 		}
 
-		// If the request count exceeds the limit, block the request
-		if count > 5 {
-			ctx.JSON(429, gin.H{
-				"error": "Rate limit exceeded",
-			})
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		ctx.Header("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(seconds(limit.Window)))
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(max(seconds(result.RetryAfter), 1)))
+			ctx.Error(utils.ErrRateLimitExceeded)
 			ctx.Abort()
 			return
 		}
 
-		// Set the expiration for the rate limit window if this is the first request
-		if count == 1 {
-			redis.Expire(key, 1*time.Hour)
-		}
-
 		ctx.Next() // Continue to the next handler if not rate limited
 	}
 }
+
+// seconds rounds a duration up to whole seconds, as used by rate limit headers
+func seconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
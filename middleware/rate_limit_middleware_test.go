@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"urlshortener/limiter"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimiter checks that limits depend on the plan and that rate limit headers are returned
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := limiter.ParseRules("shorten:anonymous=2/1h,shorten:pro=0/1h")
+	require.NoError(t, err)
+	rateLimiter := NewRateLimiter(limiter.NewMemoryLimiter(), rules, KeyByUser, FailOpen)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware(), func(ctx *gin.Context) {
+		if user := ctx.GetHeader("X-Test-User"); user != "" {
+			ctx.Set(UserIdKey, user)
+			ctx.Set(PlanKey, "pro")
+		}
+	})
+	router.POST("/shorten", rateLimiter.Route("shorten"), func(ctx *gin.Context) { ctx.Status(201) })
+
+	send := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/shorten", nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send("")
+	require.Equal(t, 201, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "2;w=3600", rec.Header().Get("RateLimit-Policy"))
+
+	require.Equal(t, 201, send("").Code)
+
+	rec = send("")
+	require.Equal(t, 429, rec.Code)
+	require.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, strconv.Itoa(int((30 * time.Minute).Seconds())), rec.Header().Get("Retry-After"))
+
+	// The pro plan is not limited
+	for range 5 {
+		rec = send("alice")
+		require.Equal(t, 201, rec.Code)
+		require.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	}
+}
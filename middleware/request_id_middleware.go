@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIdHeader is the header used to receive and return the request id
+const RequestIdHeader = "X-Request-Id"
+
+// RequestIdKey is the Gin context key holding the request id
+const RequestIdKey = "request_id"
+
+// RequestIdMiddleware is a Gin middleware that assigns an id to every request.
+// The id is taken from the X-Request-Id header if the client sent one, otherwise a new UUID is generated.
+// It is stored in the Gin context under RequestIdKey and echoed back in the X-Request-Id response header.
+//
+// Usage:
+//
+//	router.Use(RequestIdMiddleware())
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader(RequestIdHeader)
+		if requestId == "" || len(requestId) > 128 {
+			requestId = uuid.New().String() // Generate a new id if missing or unreasonably long
+		}
+
+		ctx.Set(RequestIdKey, requestId)
+		ctx.Header(RequestIdHeader, requestId)
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceKey is the Gin context key holding the *models.Workspace a request is scoped to
+const WorkspaceKey = "workspace"
+
+// RoleKey is the Gin context key holding the models.Role of the client in the workspace
+const RoleKey = "workspace_role"
+
+// WorkspaceAuthorizer looks up the role of accounts in workspaces (e.g., services.WorkspaceService)
+type WorkspaceAuthorizer interface {
+	// Membership returns a workspace and the role of account in it,
+	// or utils.ErrUnauthorized or utils.ErrWorkspaceNotFound.
+	Membership(ctx context.Context, account *models.Account, workspaceId int) (*models.Workspace, models.Role, error)
+}
+
+// RequireWorkspaceRole is a Gin middleware that only lets members of the workspace in the :id path parameter
+// with at least the given role through.
+// Anonymous clients get HTTP 401 (Unauthorized), non-members HTTP 404 (Not Found) so workspaces can't be
+// discovered, and members with a lower role HTTP 403 (Forbidden).
+// The workspace is stored under WorkspaceKey and the role of the client under RoleKey.
+//
+// Usage:
+//
+//	workspaces.POST("/:id/links", RequireWorkspaceRole(workspaceService, models.RoleEditor), handler)
+func RequireWorkspaceRole(workspaces WorkspaceAuthorizer, min models.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			ctx.Error(utils.ErrWorkspaceNotFound)
+			ctx.Abort()
+			return
+		}
+
+		workspace, role, err := workspaces.Membership(ctx.Request.Context(), CurrentAccount(ctx), id)
+		if err != nil {
+			ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+		if !role.AtLeast(min) {
+			ctx.Error(utils.ErrForbidden)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(WorkspaceKey, workspace)
+		ctx.Set(RoleKey, role)
+		ctx.Next()
+	}
+}
+
+// CurrentWorkspace returns the workspace of a request authorized by RequireWorkspaceRole, or nil
+func CurrentWorkspace(ctx *gin.Context) *models.Workspace {
+	workspace, _ := ctx.Value(WorkspaceKey).(*models.Workspace)
+	return workspace
+}
+
+// CurrentRole returns the role of the client in the workspace of a request authorized by RequireWorkspaceRole
+func CurrentRole(ctx *gin.Context) models.Role {
+	role, _ := ctx.Value(RoleKey).(models.Role)
+	return role
+}
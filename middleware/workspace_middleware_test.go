@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorkspaces knows workspace 1, where the account of fakeAuthenticator is an editor
+type fakeWorkspaces struct{}
+
+func (fakeWorkspaces) Membership(ctx context.Context, account *models.Account, workspaceId int) (*models.Workspace, models.Role, error) {
+	switch {
+	case account == nil:
+		return nil, "", utils.ErrUnauthorized
+	case workspaceId != 1:
+		return nil, "", utils.ErrWorkspaceNotFound
+	}
+	daily := 2
+	return &models.Workspace{Id: 1, Plan: quota.PlanFree, DailyQuota: &daily}, models.RoleEditor, nil
+}
+
+// TestRequireWorkspaceRole checks that members need a high enough role
+// and that links created in a workspace count against the quotas of the workspace and of the account
+func TestRequireWorkspaceRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	plans := quota.Plans{quota.PlanAnonymous: {Daily: 2, Monthly: 10}, quota.PlanFree: {Daily: 5, Monthly: 10}}
+	quotas := services.NewQuotaService(quota.NewMemoryCounter(), plans)
+
+	router := gin.New()
+	router.Use(ErrorMiddleware(), AuthMiddleware(fakeAuthenticator{}))
+	created := func(ctx *gin.Context) { ctx.Status(201) }
+	router.GET("/workspaces/:id/links", RequireWorkspaceRole(fakeWorkspaces{}, models.RoleViewer), func(ctx *gin.Context) {
+		ctx.String(200, string(CurrentRole(ctx)))
+	})
+	router.POST("/workspaces/:id/links", RequireWorkspaceRole(fakeWorkspaces{}, models.RoleEditor), QuotaMiddleware(quotas, FailOpen), created)
+	router.POST("/workspaces/:id/invitations", RequireWorkspaceRole(fakeWorkspaces{}, models.RoleAdmin), created)
+	router.POST("/shorten", QuotaMiddleware(quotas, FailOpen), created)
+
+	send := func(method string, path string, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		apiKey string
+		status int
+	}{
+		{"anonymous", http.MethodGet, "/workspaces/1/links", "", 401},
+		{"not a member", http.MethodGet, "/workspaces/2/links", "good-key", 404},
+		{"not a number", http.MethodGet, "/workspaces/abc/links", "good-key", 404},
+		{"role too low", http.MethodPost, "/workspaces/1/invitations", "good-key", 403},
+		{"higher role", http.MethodGet, "/workspaces/1/links", "good-key", 200},
+		{"exact role", http.MethodPost, "/workspaces/1/links", "good-key", 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.status, send(tt.method, tt.path, tt.apiKey).Code)
+		})
+	}
+	require.Equal(t, "editor", send(http.MethodGet, "/workspaces/1/links", "good-key").Body.String())
+
+	// Workspace links count against the account creating them too, so a workspace can't lift the account's own quota
+	rec := send(http.MethodPost, "/workspaces/1/links", "good-key")
+	require.Equal(t, 429, rec.Code)
+	require.Equal(t, "0", rec.Header().Get("X-Quota-Daily-Remaining"))
+	require.Equal(t, 429, send(http.MethodPost, "/shorten", "good-key").Code)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Account represents a registered client of the API, authenticated with API keys.
+type Account struct {
+	Id           int       // Unique identifier for the account record
+	Email        string    // Contact email, unique per account
+	Plan         string    // Quota plan (e.g., free, pro, unlimited)
+	DailyQuota   *int      // Overrides the daily link quota of the plan (nil uses the plan's)
+	MonthlyQuota *int      // Overrides the monthly link quota of the plan (nil uses the plan's)
+	Admin        bool      // Whether the account may manage other accounts
+	CreatedAt    time.Time // Timestamp when the account was created
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// UTM holds the Urchin Tracking Module parameters appended to destinations.
+type UTM struct {
+	Source   string `json:"source,omitempty"`   // utm_source (e.g., newsletter)
+	Medium   string `json:"medium,omitempty"`   // utm_medium (e.g., email)
+	Campaign string `json:"campaign,omitempty"` // utm_campaign (e.g., spring_sale)
+	Term     string `json:"term,omitempty"`     // utm_term (e.g., paid keywords)
+	Content  string `json:"content,omitempty"`  // utm_content (e.g., header_link)
+}
+
+// Params returns the non-empty fields as query parameters, in the usual order
+func (u UTM) Params() [][2]string {
+	var params [][2]string
+	for _, param := range [][2]string{
+		{"utm_source", u.Source},
+		{"utm_medium", u.Medium},
+		{"utm_campaign", u.Campaign},
+		{"utm_term", u.Term},
+		{"utm_content", u.Content},
+	} {
+		if param[1] != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// Campaign groups links and holds the UTM parameters they default to.
+type Campaign struct {
+	Id        int       `json:"id"`         // Unique identifier for the campaign record
+	AccountId int       `json:"account_id"` // Account owning the campaign
+	Name      string    `json:"name"`       // Name, unique per account
+	UTM       UTM       `json:"utm"`        // Template of UTM parameters for the campaign's links
+	CreatedAt time.Time `json:"created_at"` // Timestamp when the campaign was created
+}
+
+// CampaignStats summarizes the clicks of every link of a campaign.
+type CampaignStats struct {
+	CampaignId int            `json:"campaign_id"` // Campaign
+	Name       string         `json:"name"`        // Campaign name
+	Links      int            `json:"links"`       // Number of links in the campaign
+	Total      int            `json:"total"`       // Clicks of all links
+	ByLink     map[string]int `json:"by_link"`     // Clicks by short code
+	Countries  map[string]int `json:"countries"`   // Clicks by country code ("" for unknown)
+	Devices    map[string]int `json:"devices"`     // Clicks by device class
+}
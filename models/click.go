@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Click is a single redirect through a short URL.
+type Click struct {
+	ShortURL    string    // Short code that was followed
+	ClickedAt   time.Time // Time of the redirect
+	Destination string    // URL the visitor was sent to
+	Country     string    // ISO 3166-1 alpha-2 country code of the visitor (empty if unknown)
+	OS          string    // Operating system parsed from the User-Agent
+	Device      string    // Device class parsed from the User-Agent
+	Browser     string    // Browser parsed from the User-Agent
+	Referrer    string    // Host of the Referer header (empty if absent)
+	Variant     string    // Split variant the visitor was sent to (empty if none)
+}
+
+// ClickStats summarizes the clicks of a short URL.
+type ClickStats struct {
+	ShortURL  string         `json:"short_code"` // Short code
+	Total     int            `json:"total"`      // Number of clicks
+	Countries map[string]int `json:"countries"`  // Clicks by country code ("" for unknown)
+	OS        map[string]int `json:"os"`         // Clicks by operating system
+	Devices   map[string]int `json:"devices"`    // Clicks by device class
+	Browsers  map[string]int `json:"browsers"`   // Clicks by browser
+	Referrers map[string]int `json:"referrers"`  // Clicks by referring host ("" for direct visits)
+}
+
+// VariantClicks counts the clicks of one variant of a split link.
+type VariantClicks struct {
+	Name   string `json:"name"`   // Variant name
+	URL    string `json:"url"`    // Destination of the variant
+	Weight int    `json:"weight"` // Configured weight
+	Clicks int    `json:"clicks"` // Visitors sent to the variant
+}
+
+// VariantStats summarizes the clicks of each variant of a split link.
+type VariantStats struct {
+	ShortURL string          `json:"short_code"` // Short code
+	Total    int             `json:"total"`      // Clicks sent to any variant
+	Variants []VariantClicks `json:"variants"`   // Clicks by variant, in configured order
+}
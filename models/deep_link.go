@@ -0,0 +1,24 @@
+package models
+
+import "urlshortener/useragent"
+
+// DeepLink holds the app URIs tried on mobile before falling back to the web destination of a link.
+type DeepLink struct {
+	IOS     string `json:"ios,omitempty"`     // App URI opened on iOS (e.g., myapp://product/42)
+	Android string `json:"android,omitempty"` // App URI opened on Android (e.g., myapp://product/42 or an intent: URI)
+}
+
+// AppURI returns the app URI for the platform of agent, or an empty string if there is none
+// Bots never get an app URI so link previews and crawlers see the web destination
+func (d *DeepLink) AppURI(agent useragent.Agent) string {
+	if d == nil || agent.Device == "bot" {
+		return ""
+	}
+	switch agent.OS {
+	case "ios":
+		return d.IOS
+	case "android":
+		return d.Android
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Domain is a custom host serving the short links of an account (e.g., go.example.com).
+type Domain struct {
+	Id                int        `json:"id"`          // Unique identifier for the domain record
+	AccountId         int        `json:"account_id"`  // Account owning the domain
+	Host              string     `json:"host"`        // Lower-case host name without port
+	VerificationToken string     `json:"-"`           // Random token the owner publishes in a DNS TXT record
+	VerifiedAt        *time.Time `json:"verified_at"` // When ownership was verified (nil until then)
+	CreatedAt         time.Time  `json:"created_at"`  // Timestamp when the domain was added
+}
+
+// Verified reports whether ownership of the domain has been verified
+func (d *Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// VerificationRecord returns the name and value of the DNS TXT record proving ownership of the domain
+func (d *Domain) VerificationRecord() (name string, value string) {
+	return "_shortener-verification." + d.Host, "shortener-verification=" + d.VerificationToken
+}
+
+// LinkKey identifies a short link across domains: the short code itself on the default domain,
+// "<host>/<code>" on a custom domain
+// Repositories, caches and click analytics use it wherever they take a short code
+func LinkKey(domain string, shortCode string) string {
+	if domain == "" {
+		return shortCode
+	}
+	return domain + "/" + shortCode
+}
+
+// SplitLinkKey returns the domain ("" for the default domain) and short code of a LinkKey
+func SplitLinkKey(key string) (domain string, shortCode string) {
+	if domain, shortCode, ok := strings.Cut(key, "/"); ok {
+		return domain, shortCode
+	}
+	return "", key
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Expiry states links can be filtered by
+const (
+	ExpiryActive  = "active"  // Links that never expire or haven't expired yet
+	ExpiryExpired = "expired" // Links past their expiration
+	ExpiryNever   = "never"   // Links without expiration
+)
+
+// LinkFilter selects links to list; zero fields don't filter.
+type LinkFilter struct {
+	Query         string     // Full-text search over the destination and title
+	Tags          []string   // Tags the links must all have
+	Domain        *string    // Domain of the links ("" for the default domain)
+	Folder        *string    // Folder of the links ("" for links outside folders)
+	CreatedAfter  *time.Time // Only links created at or after this time
+	CreatedBefore *time.Time // Only links created before this time
+	Expiry        string     // ExpiryActive, ExpiryExpired or ExpiryNever
+	CreatedBy     *int       // Account that created the links
+	WorkspaceId   *int       // Workspace owning the links
+	VisibleTo     *int       // Only links created by this account or in its workspaces (nil for every link)
+	Before        int        // Only links with a lower id, the cursor of the previous page (0 for the first page)
+	Limit         int        // Maximum number of links returned
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"urlshortener/useragent"
+)
+
+// RoutingRule sends visitors whose User-Agent matches every set field to URL instead of the link's default destination.
+// Empty fields match anything.
+type RoutingRule struct {
+	OS      string `json:"os,omitempty"`      // ios, android, windows, macos, linux, chromeos or other
+	Device  string `json:"device,omitempty"`  // mobile, tablet, desktop or bot
+	Browser string `json:"browser,omitempty"` // chrome, safari, firefox, edge, opera, samsung, ie or other
+	URL     string `json:"url"`               // Alternate destination
+}
+
+// Matches reports whether agent satisfies every field set on the rule
+func (r RoutingRule) Matches(agent useragent.Agent) bool {
+	return matchField(r.OS, agent.OS) && matchField(r.Device, agent.Device) && matchField(r.Browser, agent.Browser)
+}
+
+// matchField reports whether want is empty or equal to got, ignoring case
+func matchField(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// Targeted reports whether the destination of the link depends on the visitor
+func (u *Url) Targeted() bool {
+	return len(u.Rules) > 0 || len(u.Countries) > 0 || u.Split != nil || u.DeepLink != nil
+}
+
+// Target returns the URL of the first rule matching agent, else the URL of the visitor's country (ISO code, empty if unknown)
+// Returns false if neither matches
+func (u *Url) Target(agent useragent.Agent, country string) (string, bool) {
+	for _, rule := range u.Rules {
+		if rule.Matches(agent) {
+			return rule.URL, true
+		}
+	}
+	if target, ok := u.Countries[country]; ok && country != "" {
+		return target, true
+	}
+	return "", false
+}
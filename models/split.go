@@ -0,0 +1,45 @@
+package models
+
+// Variant is one weighted destination of a split link.
+type Variant struct {
+	Name   string `json:"name"`   // Unique name within the split (e.g., a, b, control)
+	URL    string `json:"url"`    // Destination of the variant
+	Weight int    `json:"weight"` // Relative share of visits (e.g., 70 and 30)
+}
+
+// Split divides the visits of a link among weighted variants.
+type Split struct {
+	Sticky   bool      `json:"sticky,omitempty"` // Remember the variant of a visitor in a cookie
+	Variants []Variant `json:"variants"`         // Destinations and their weights
+}
+
+// TotalWeight returns the sum of the weights of every variant
+func (s *Split) TotalWeight() int {
+	total := 0
+	for _, variant := range s.Variants {
+		total += variant.Weight
+	}
+	return total
+}
+
+// Pick returns the variant at position roll (0 <= roll < TotalWeight) of the cumulative weights,
+// so a uniformly random roll picks each variant in proportion to its weight
+func (s *Split) Pick(roll int) Variant {
+	for _, variant := range s.Variants {
+		if roll < variant.Weight {
+			return variant
+		}
+		roll -= variant.Weight
+	}
+	return s.Variants[len(s.Variants)-1]
+}
+
+// Variant returns the variant called name, or nil if there is none
+func (s *Split) Variant(name string) *Variant {
+	for i := range s.Variants {
+		if s.Variants[i].Name == name {
+			return &s.Variants[i]
+		}
+	}
+	return nil
+}
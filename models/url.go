@@ -4,9 +4,29 @@ import "time"
 
 // Url represents a shortened URL mapping with metadata.
 type Url struct {
-	Id        int       // Unique identifier for the URL record
-	URL       string    // Original (long) URL
-	ShortURL  string    // Generated short code for the URL
-	CreatedAt time.Time // Timestamp when the short URL was created
-	Expire    time.Time // Expiration time for the short URL (same as CreatedAt if no expiration)
+	Id           int               // Unique identifier for the URL record
+	URL          string            // Original (long) URL
+	ShortURL     string            // Generated short code for the URL
+	Domain       string            // Custom domain serving the link ("" for the default domain), codes are unique per domain
+	CreatedAt    time.Time         // Timestamp when the short URL was created
+	Expire       time.Time         // Expiration time for the short URL (same as CreatedAt if no expiration)
+	Preview      bool              // Always show the preview page instead of redirecting
+	Rules        []RoutingRule     // Alternate destinations chosen by the visitor's User-Agent, first match wins
+	Countries    map[string]string // Alternate destinations by ISO 3166-1 alpha-2 country code of the visitor (e.g., US)
+	Split        *Split            // Weighted destinations replacing URL for visitors not matched by Rules or Countries (nil if none)
+	ForwardQuery bool              // Merge the query string of the request into the destination
+	ForwardPath  bool              // Append path segments following the short code to the destination
+	CampaignId   *int              // Campaign the link belongs to (nil if none)
+	DeepLink     *DeepLink         // App URIs tried on mobile before the web destination (nil if none)
+	WorkspaceId  *int              // Workspace owning the link (nil for links created outside of workspaces)
+	CreatedBy    *int              // Account that created the link (nil for anonymous links)
+	Title        string            // Title shown in link listings and matched by search ("" if none)
+	Notes        string            // Free-form notes of the link's owners ("" if none)
+	Folder       string            // Folder the link is filed under ("" if none)
+	Tags         []string          // Normalized tags, sorted
+}
+
+// Key returns the LinkKey of the link
+func (u *Url) Key() string {
+	return LinkKey(u.Domain, u.ShortURL)
 }
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Role is the level of access of a member to a workspace.
+type Role string
+
+// Roles of workspace members, from most to least privileged
+const (
+	RoleOwner  Role = "owner"  // Everything, including managing owners
+	RoleAdmin  Role = "admin"  // Manages members below owner and invitations
+	RoleEditor Role = "editor" // Creates, updates and deletes links
+	RoleViewer Role = "viewer" // Reads links, stats and quotas
+)
+
+// roleRanks orders the roles, higher ranks include the permissions of lower ones
+var roleRanks = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3, RoleOwner: 4}
+
+// Valid reports whether r is one of the known roles
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// AtLeast reports whether r grants every permission of min (unknown roles grant nothing)
+func (r Role) AtLeast(min Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[min]
+}
+
+// Workspace owns links and shares them between its members.
+type Workspace struct {
+	Id           int       `json:"id"`                      // Unique identifier for the workspace record
+	Name         string    `json:"name"`                    // Display name
+	Plan         string    `json:"plan"`                    // Quota plan of the workspace's links (e.g., free, pro, unlimited)
+	DailyQuota   *int      `json:"daily_quota,omitempty"`   // Overrides the daily link quota of the plan (nil uses the plan's)
+	MonthlyQuota *int      `json:"monthly_quota,omitempty"` // Overrides the monthly link quota of the plan (nil uses the plan's)
+	CreatedAt    time.Time `json:"created_at"`              // Timestamp when the workspace was created
+}
+
+// Member is an account belonging to a workspace with a role.
+type Member struct {
+	WorkspaceId int       `json:"workspace_id"` // Workspace the account belongs to
+	AccountId   int       `json:"account_id"`   // Member account
+	Email       string    `json:"email"`        // Email of the member account
+	Role        Role      `json:"role"`         // Access level in the workspace
+	CreatedAt   time.Time `json:"created_at"`   // Timestamp when the account joined
+}
+
+// Membership is a workspace seen by one of its members.
+type Membership struct {
+	Workspace
+	Role Role `json:"role"` // Access level of the member
+}
+
+// Invitation offers a role in a workspace to the holder of a single-use token.
+type Invitation struct {
+	Id          int        `json:"id"`           // Unique identifier for the invitation record
+	WorkspaceId int        `json:"workspace_id"` // Workspace the invitation is for
+	Email       string     `json:"email"`        // Email of the account allowed to accept it
+	Role        Role       `json:"role"`         // Role given on acceptance
+	TokenHash   string     `json:"-"`            // SHA-256 hash of the token, the token itself is never stored
+	InvitedBy   int        `json:"invited_by"`   // Account that sent the invitation
+	ExpiresAt   time.Time  `json:"expires_at"`   // Deadline for accepting
+	AcceptedAt  *time.Time `json:"accepted_at"`  // When it was accepted (nil until then)
+	CreatedAt   time.Time  `json:"created_at"`   // Timestamp when the invitation was sent
+}
+
+// WorkspaceStats aggregates the clicks of every link of a workspace.
+type WorkspaceStats struct {
+	WorkspaceId int            `json:"workspace_id"` // Workspace the statistics are for
+	Links       int            `json:"links"`        // Number of links in the workspace
+	Total       int            `json:"total"`        // Clicks on all the links
+	ByLink      map[string]int `json:"by_link"`      // Clicks by link key
+	Countries   map[string]int `json:"countries"`    // Clicks by ISO country code
+	Devices     map[string]int `json:"devices"`      // Clicks by device type
+}
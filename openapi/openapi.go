@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"log/slog"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// specJson is the OpenAPI 3 document describing every HTTP route
+//
+//go:embed openapi.json
+var specJson []byte
+
+// swaggerHtml is the Swagger UI page rendering specJson
+//
+//go:embed swagger.html
+var swaggerHtml []byte
+
+// Load parses and validates the embedded OpenAPI document
+// Returns the parsed document or an error if it is malformed
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(specJson)
+	if err != nil {
+		slog.Error(" [openapi.go] [LOAD SPEC] ", slog.Any("error", err))
+		return nil, err
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		slog.Error(" [openapi.go] [VALIDATE SPEC] ", slog.Any("error", err))
+		return nil, err
+	}
+	return spec, nil
+}
+
+// SpecHandler handles GET /openapi.json requests by serving the embedded OpenAPI document
+func SpecHandler(ctx *gin.Context) {
+	ctx.Data(200, "application/json; charset=utf-8", specJson)
+}
+
+// SwaggerUIHandler handles GET /docs requests by serving the Swagger UI page
+func SwaggerUIHandler(ctx *gin.Context) {
+	ctx.Data(200, "text/html; charset=utf-8", swaggerHtml)
+}
+
+// PathFromGin converts a Gin route path (e.g., /fetch/:code) to an OpenAPI path (e.g., /fetch/{code})
+func PathFromGin(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,5 @@
+// Package pb contains the Go code generated from proto/url_service.proto.
+// Regenerate it with `go generate ./pb` (requires buf, protoc-gen-go and protoc-gen-go-grpc on PATH).
+package pb
+
+//go:generate sh -c "cd ../proto && buf generate"
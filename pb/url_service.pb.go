@@ -0,0 +1,602 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: url_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ShortenRequest is the payload for creating a short URL.
+type ShortenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                            // The original URL to shorten
+	ExpireIn      int64                  `protobuf:"varint,2,opt,name=expire_in,json=expireIn,proto3" json:"expire_in,omitempty"` // Expiration in minutes (0 means no expiration)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
+	mi := &file_url_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenRequest) ProtoMessage() {}
+
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ShortenRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetExpireIn() int64 {
+	if x != nil {
+		return x.ExpireIn
+	}
+	return 0
+}
+
+// ShortenResponse is returned after a short URL has been created.
+type ShortenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"` // Generated short code
+	ShortUrl      string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`    // Short code with SHORT_URL_PREFIX prepended
+	ExpireAt      string                 `protobuf:"bytes,3,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`    // Formatted expiration time or "no expiration"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
+	mi := &file_url_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenResponse) ProtoMessage() {}
+
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ShortenResponse) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *ShortenResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ShortenResponse) GetExpireAt() string {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return ""
+}
+
+// ResolveRequest looks up the original URL of a short code.
+type ResolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"` // Short code to resolve
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_url_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResolveRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+// ResolveResponse carries the original URL of a short code.
+type ResolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // Original (long) URL
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_url_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResolveResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// GetMetadataRequest looks up the metadata of a short code.
+type GetMetadataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"` // Short code to look up
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetadataRequest) Reset() {
+	*x = GetMetadataRequest{}
+	mi := &file_url_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetadataRequest) ProtoMessage() {}
+
+func (x *GetMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetadataRequest.ProtoReflect.Descriptor instead.
+func (*GetMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetMetadataRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+// UrlMetadata describes a stored short URL.
+type UrlMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                              // Original (long) URL
+	ShortCode     string                 `protobuf:"bytes,2,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"` // Short code
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Creation time
+	ExpireAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`    // Expiration time (unset if the URL never expires)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UrlMetadata) Reset() {
+	*x = UrlMetadata{}
+	mi := &file_url_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UrlMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UrlMetadata) ProtoMessage() {}
+
+func (x *UrlMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UrlMetadata.ProtoReflect.Descriptor instead.
+func (*UrlMetadata) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UrlMetadata) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *UrlMetadata) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UrlMetadata) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *UrlMetadata) GetExpireAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return nil
+}
+
+// UpdateRequest changes an existing short code.
+// Unset fields are left unchanged.
+type UpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"`     // Short code to update
+	Url           *string                `protobuf:"bytes,2,opt,name=url,proto3,oneof" json:"url,omitempty"`                            // New original URL
+	ExpireIn      *int64                 `protobuf:"varint,3,opt,name=expire_in,json=expireIn,proto3,oneof" json:"expire_in,omitempty"` // New expiration in minutes from now (0 removes the expiration)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_url_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetExpireIn() int64 {
+	if x != nil && x.ExpireIn != nil {
+		return *x.ExpireIn
+	}
+	return 0
+}
+
+// DeleteRequest removes a short code.
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortCode     string                 `protobuf:"bytes,1,opt,name=short_code,json=shortCode,proto3" json:"short_code,omitempty"` // Short code to delete
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_url_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteRequest) GetShortCode() string {
+	if x != nil {
+		return x.ShortCode
+	}
+	return ""
+}
+
+// DeleteResponse is returned after a short code has been deleted.
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_url_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_url_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_url_service_proto_rawDescGZIP(), []int{8}
+}
+
+var File_url_service_proto protoreflect.FileDescriptor
+
+const file_url_service_proto_rawDesc = "" +
+	"\n" +
+	"\x11url_service.proto\x12\x0furlshortener.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"?\n" +
+	"\x0eShortenRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1b\n" +
+	"\texpire_in\x18\x02 \x01(\x03R\bexpireIn\"j\n" +
+	"\x0fShortenResponse\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\x12\x1b\n" +
+	"\texpire_at\x18\x03 \x01(\tR\bexpireAt\"/\n" +
+	"\x0eResolveRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"#\n" +
+	"\x0fResolveResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\"3\n" +
+	"\x12GetMetadataRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\xb2\x01\n" +
+	"\vUrlMetadata\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x02 \x01(\tR\tshortCode\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\texpire_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\"}\n" +
+	"\rUpdateRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\x12\x15\n" +
+	"\x03url\x18\x02 \x01(\tH\x00R\x03url\x88\x01\x01\x12 \n" +
+	"\texpire_in\x18\x03 \x01(\x03H\x01R\bexpireIn\x88\x01\x01B\x06\n" +
+	"\x04_urlB\f\n" +
+	"\n" +
+	"_expire_in\".\n" +
+	"\rDeleteRequest\x12\x1d\n" +
+	"\n" +
+	"short_code\x18\x01 \x01(\tR\tshortCode\"\x10\n" +
+	"\x0eDeleteResponse2\x8f\x03\n" +
+	"\fUrlShortener\x12L\n" +
+	"\aShorten\x12\x1f.urlshortener.v1.ShortenRequest\x1a .urlshortener.v1.ShortenResponse\x12L\n" +
+	"\aResolve\x12\x1f.urlshortener.v1.ResolveRequest\x1a .urlshortener.v1.ResolveResponse\x12P\n" +
+	"\vGetMetadata\x12#.urlshortener.v1.GetMetadataRequest\x1a\x1c.urlshortener.v1.UrlMetadata\x12F\n" +
+	"\x06Update\x12\x1e.urlshortener.v1.UpdateRequest\x1a\x1c.urlshortener.v1.UrlMetadata\x12I\n" +
+	"\x06Delete\x12\x1e.urlshortener.v1.DeleteRequest\x1a\x1f.urlshortener.v1.DeleteResponseB\x11Z\x0furlshortener/pbb\x06proto3"
+
+var (
+	file_url_service_proto_rawDescOnce sync.Once
+	file_url_service_proto_rawDescData []byte
+)
+
+func file_url_service_proto_rawDescGZIP() []byte {
+	file_url_service_proto_rawDescOnce.Do(func() {
+		file_url_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_url_service_proto_rawDesc), len(file_url_service_proto_rawDesc)))
+	})
+	return file_url_service_proto_rawDescData
+}
+
+var file_url_service_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_url_service_proto_goTypes = []any{
+	(*ShortenRequest)(nil),        // 0: urlshortener.v1.ShortenRequest
+	(*ShortenResponse)(nil),       // 1: urlshortener.v1.ShortenResponse
+	(*ResolveRequest)(nil),        // 2: urlshortener.v1.ResolveRequest
+	(*ResolveResponse)(nil),       // 3: urlshortener.v1.ResolveResponse
+	(*GetMetadataRequest)(nil),    // 4: urlshortener.v1.GetMetadataRequest
+	(*UrlMetadata)(nil),           // 5: urlshortener.v1.UrlMetadata
+	(*UpdateRequest)(nil),         // 6: urlshortener.v1.UpdateRequest
+	(*DeleteRequest)(nil),         // 7: urlshortener.v1.DeleteRequest
+	(*DeleteResponse)(nil),        // 8: urlshortener.v1.DeleteResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_url_service_proto_depIdxs = []int32{
+	9, // 0: urlshortener.v1.UrlMetadata.created_at:type_name -> google.protobuf.Timestamp
+	9, // 1: urlshortener.v1.UrlMetadata.expire_at:type_name -> google.protobuf.Timestamp
+	0, // 2: urlshortener.v1.UrlShortener.Shorten:input_type -> urlshortener.v1.ShortenRequest
+	2, // 3: urlshortener.v1.UrlShortener.Resolve:input_type -> urlshortener.v1.ResolveRequest
+	4, // 4: urlshortener.v1.UrlShortener.GetMetadata:input_type -> urlshortener.v1.GetMetadataRequest
+	6, // 5: urlshortener.v1.UrlShortener.Update:input_type -> urlshortener.v1.UpdateRequest
+	7, // 6: urlshortener.v1.UrlShortener.Delete:input_type -> urlshortener.v1.DeleteRequest
+	1, // 7: urlshortener.v1.UrlShortener.Shorten:output_type -> urlshortener.v1.ShortenResponse
+	3, // 8: urlshortener.v1.UrlShortener.Resolve:output_type -> urlshortener.v1.ResolveResponse
+	5, // 9: urlshortener.v1.UrlShortener.GetMetadata:output_type -> urlshortener.v1.UrlMetadata
+	5, // 10: urlshortener.v1.UrlShortener.Update:output_type -> urlshortener.v1.UrlMetadata
+	8, // 11: urlshortener.v1.UrlShortener.Delete:output_type -> urlshortener.v1.DeleteResponse
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_url_service_proto_init() }
+func file_url_service_proto_init() {
+	if File_url_service_proto != nil {
+		return
+	}
+	file_url_service_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_url_service_proto_rawDesc), len(file_url_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_url_service_proto_goTypes,
+		DependencyIndexes: file_url_service_proto_depIdxs,
+		MessageInfos:      file_url_service_proto_msgTypes,
+	}.Build()
+	File_url_service_proto = out.File
+	file_url_service_proto_goTypes = nil
+	file_url_service_proto_depIdxs = nil
+}
@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: url_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UrlShortener_Shorten_FullMethodName     = "/urlshortener.v1.UrlShortener/Shorten"
+	UrlShortener_Resolve_FullMethodName     = "/urlshortener.v1.UrlShortener/Resolve"
+	UrlShortener_GetMetadata_FullMethodName = "/urlshortener.v1.UrlShortener/GetMetadata"
+	UrlShortener_Update_FullMethodName      = "/urlshortener.v1.UrlShortener/Update"
+	UrlShortener_Delete_FullMethodName      = "/urlshortener.v1.UrlShortener/Delete"
+)
+
+// UrlShortenerClient is the client API for UrlShortener service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// UrlShortener exposes the URL shortening service over gRPC.
+// It mirrors the HTTP API served by Gin and shares the same UrlService.
+type UrlShortenerClient interface {
+	// Shorten creates a new short code for the given URL.
+	Shorten(ctx context.Context, in *ShortenRequest, opts ...grpc.CallOption) (*ShortenResponse, error)
+	// Resolve returns the original URL for a short code.
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+	// GetMetadata returns the stored metadata of a short code.
+	GetMetadata(ctx context.Context, in *GetMetadataRequest, opts ...grpc.CallOption) (*UrlMetadata, error)
+	// Update changes the destination and/or expiration of a short code.
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UrlMetadata, error)
+	// Delete removes a short code.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+}
+
+type urlShortenerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUrlShortenerClient(cc grpc.ClientConnInterface) UrlShortenerClient {
+	return &urlShortenerClient{cc}
+}
+
+func (c *urlShortenerClient) Shorten(ctx context.Context, in *ShortenRequest, opts ...grpc.CallOption) (*ShortenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShortenResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_Shorten_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_Resolve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) GetMetadata(ctx context.Context, in *GetMetadataRequest, opts ...grpc.CallOption) (*UrlMetadata, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UrlMetadata)
+	err := c.cc.Invoke(ctx, UrlShortener_GetMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UrlMetadata, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UrlMetadata)
+	err := c.cc.Invoke(ctx, UrlShortener_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *urlShortenerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, UrlShortener_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UrlShortenerServer is the server API for UrlShortener service.
+// All implementations must embed UnimplementedUrlShortenerServer
+// for forward compatibility.
+//
+// UrlShortener exposes the URL shortening service over gRPC.
+// It mirrors the HTTP API served by Gin and shares the same UrlService.
+type UrlShortenerServer interface {
+	// Shorten creates a new short code for the given URL.
+	Shorten(context.Context, *ShortenRequest) (*ShortenResponse, error)
+	// Resolve returns the original URL for a short code.
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	// GetMetadata returns the stored metadata of a short code.
+	GetMetadata(context.Context, *GetMetadataRequest) (*UrlMetadata, error)
+	// Update changes the destination and/or expiration of a short code.
+	Update(context.Context, *UpdateRequest) (*UrlMetadata, error)
+	// Delete removes a short code.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	mustEmbedUnimplementedUrlShortenerServer()
+}
+
+// UnimplementedUrlShortenerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUrlShortenerServer struct{}
+
+func (UnimplementedUrlShortenerServer) Shorten(context.Context, *ShortenRequest) (*ShortenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shorten not implemented")
+}
+func (UnimplementedUrlShortenerServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedUrlShortenerServer) GetMetadata(context.Context, *GetMetadataRequest) (*UrlMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
+}
+func (UnimplementedUrlShortenerServer) Update(context.Context, *UpdateRequest) (*UrlMetadata, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedUrlShortenerServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedUrlShortenerServer) mustEmbedUnimplementedUrlShortenerServer() {}
+func (UnimplementedUrlShortenerServer) testEmbeddedByValue()                      {}
+
+// UnsafeUrlShortenerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UrlShortenerServer will
+// result in compilation errors.
+type UnsafeUrlShortenerServer interface {
+	mustEmbedUnimplementedUrlShortenerServer()
+}
+
+func RegisterUrlShortenerServer(s grpc.ServiceRegistrar, srv UrlShortenerServer) {
+	// If the following call pancis, it indicates UnimplementedUrlShortenerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UrlShortener_ServiceDesc, srv)
+}
+
+func _UrlShortener_Shorten_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).Shorten(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_Shorten_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).Shorten(ctx, req.(*ShortenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_Resolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).GetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_GetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).GetMetadata(ctx, req.(*GetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UrlShortener_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UrlShortenerServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UrlShortener_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UrlShortenerServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UrlShortener_ServiceDesc is the grpc.ServiceDesc for UrlShortener service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UrlShortener_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "urlshortener.v1.UrlShortener",
+	HandlerType: (*UrlShortenerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Shorten",
+			Handler:    _UrlShortener_Shorten_Handler,
+		},
+		{
+			MethodName: "Resolve",
+			Handler:    _UrlShortener_Resolve_Handler,
+		},
+		{
+			MethodName: "GetMetadata",
+			Handler:    _UrlShortener_GetMetadata_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _UrlShortener_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _UrlShortener_Delete_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "url_service.proto",
+}
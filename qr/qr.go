@@ -0,0 +1,188 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Size and margin bounds accepted by Render
+const (
+	MinSize   = 64   // Smallest image, in pixels
+	MaxSize   = 2048 // Largest image, in pixels
+	MaxMargin = 16   // Widest quiet zone, in modules
+)
+
+// ErrInvalidOptions is returned by Render when an option is out of range or malformed
+var ErrInvalidOptions = errors.New("invalid QR code options")
+
+// Format is the image format of a QR code
+type Format string
+
+const (
+	FormatPNG Format = "png" // Raster image (image/png)
+	FormatSVG Format = "svg" // Vector image (image/svg+xml)
+)
+
+// ContentType returns the media type of images in format f
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Format     Format      // Image format
+	Size       int         // Width and height in pixels (SVG: intrinsic size)
+	Margin     int         // Quiet zone around the code, in modules
+	Level      string      // Error correction level: L (7%), M (15%), Q (25%) or H (30%)
+	Foreground color.NRGBA // Color of dark modules
+	Background color.NRGBA // Color of light modules and the margin
+}
+
+// DefaultOptions renders a 256px black on white PNG with the standard 4-module margin and 15% error correction
+var DefaultOptions = Options{
+	Format:     FormatPNG,
+	Size:       256,
+	Margin:     4,
+	Level:      "M",
+	Foreground: color.NRGBA{A: 0xff},
+	Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+}
+
+// levels maps error correction levels to the encoder's constants
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// ParseColor parses a hex color such as "1f6feb", "#1f6feb" or "#1f6feb80" (with alpha)
+func ParseColor(value string) (color.NRGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 && len(value) != 8 {
+		return color.NRGBA{}, ErrInvalidOptions
+	}
+	number, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, ErrInvalidOptions
+	}
+	if len(value) == 6 {
+		number = number<<8 | 0xff // Opaque
+	}
+	return color.NRGBA{R: uint8(number >> 24), G: uint8(number >> 16), B: uint8(number >> 8), A: uint8(number)}, nil
+}
+
+// Key returns a string identifying the options, used to cache rendered images
+func (o Options) Key() string {
+	return fmt.Sprintf("%s:%d:%d:%s:%s:%s", o.Format, o.Size, o.Margin, o.Level, hexColor(o.Foreground), hexColor(o.Background))
+}
+
+// Validate returns ErrInvalidOptions if an option is out of range
+func (o Options) Validate() error {
+	_, ok := levels[o.Level]
+	if !ok || (o.Format != FormatPNG && o.Format != FormatSVG) ||
+		o.Size < MinSize || o.Size > MaxSize || o.Margin < 0 || o.Margin > MaxMargin {
+		return ErrInvalidOptions
+	}
+	return nil
+}
+
+// Render encodes text as a QR code image
+func Render(text string, opts Options) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	code, err := qrcode.New(text, levels[opts.Level])
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true // The margin is drawn here so it can be configured
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return renderSVG(modules, opts), nil
+	}
+	return renderPNG(modules, opts)
+}
+
+// renderPNG draws modules scaled to opts.Size pixels, centering the code when the size
+// is not a multiple of the number of modules
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	total := len(modules) + 2*opts.Margin
+	scale := max(opts.Size/total, 1)
+	offset := (opts.Size - scale*total) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			left := offset + (x+opts.Margin)*scale
+			top := offset + (y+opts.Margin)*scale
+			for dy := range scale {
+				start := img.PixOffset(left, top+dy)
+				for dx := range scale {
+					img.Pix[start+dx] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSVG draws modules as a single path in a viewBox of one unit per module
+func renderSVG(modules [][]bool, opts Options) []byte {
+	total := len(modules) + 2*opts.Margin
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, total, total, svgFill(opts.Background))
+	fmt.Fprintf(&buf, `<path %s d="`, svgFill(opts.Foreground))
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			run := 1 // Merge horizontal runs of dark modules into one rectangle
+			for x+run < len(row) && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run - 1
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes()
+}
+
+// svgFill returns the fill attributes of c
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return fill
+}
+
+// hexColor formats c as an 8-digit hex color
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
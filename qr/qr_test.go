@@ -0,0 +1,60 @@
+package qr_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"urlshortener/qr"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPNG(t *testing.T) {
+	opts := qr.DefaultOptions
+	opts.Size = 300
+	opts.Foreground, _ = qr.ParseColor("#1f6feb")
+
+	data, err := qr.Render("http://127.0.0.1:3000/IrLvWOeO", opts)
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, 300, img.Bounds().Dx())
+	require.Equal(t, 300, img.Bounds().Dy())
+
+	// The corner is part of the margin, and the first module inside it is the top-left finder pattern
+	require.Equal(t, color.NRGBAModel.Convert(img.At(0, 0)), color.Color(opts.Background))
+	require.Equal(t, color.NRGBAModel.Convert(img.At(40, 40)), color.Color(opts.Foreground))
+}
+
+func TestRenderSVG(t *testing.T) {
+	opts := qr.DefaultOptions
+	opts.Format = qr.FormatSVG
+	opts.Margin = 0
+	opts.Background, _ = qr.ParseColor("ffffff00")
+
+	data, err := qr.Render("http://127.0.0.1:3000/IrLvWOeO", opts)
+	require.NoError(t, err)
+	svg := string(data)
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`))
+	require.Contains(t, svg, `fill-opacity="0.000"`)
+	require.Contains(t, svg, "M0 0h7v1h-7z") // Top row of the finder pattern
+}
+
+func TestOptionsValidate(t *testing.T) {
+	for _, mutate := range []func(*qr.Options){
+		func(o *qr.Options) { o.Size = 10 },
+		func(o *qr.Options) { o.Margin = -1 },
+		func(o *qr.Options) { o.Level = "X" },
+		func(o *qr.Options) { o.Format = "gif" },
+	} {
+		opts := qr.DefaultOptions
+		mutate(&opts)
+		require.ErrorIs(t, opts.Validate(), qr.ErrInvalidOptions)
+	}
+
+	_, err := qr.ParseColor("blue")
+	require.ErrorIs(t, err, qr.ErrInvalidOptions)
+}
@@ -0,0 +1,93 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the number of calls between two sweeps of finished periods.
+const sweepEvery = 1024
+
+// memoryCount is a counter along with the end of its period.
+type memoryCount struct {
+	count  int       // Links counted in the period
+	expire time.Time // End of the period
+}
+
+// MemoryCounter implements Counter in process memory.
+// It suits the in-memory cache backend, where a single instance serves every request.
+type MemoryCounter struct {
+	mu     sync.Mutex              // Guards the fields below
+	counts map[string]*memoryCount // Counters by period key
+	calls  int                     // Calls since the last sweep
+}
+
+// NewMemoryCounter creates a new empty MemoryCounter.
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{counts: make(map[string]*memoryCount)}
+}
+
+// get returns the counter stored under key at now. Must be called with mu held.
+func (m *MemoryCounter) get(key string, now time.Time) int {
+	if c, ok := m.counts[key]; ok && now.Before(c.expire) {
+		return c.count
+	}
+	return 0
+}
+
+// add adds delta to the counter stored under key until expire. Must be called with mu held.
+func (m *MemoryCounter) add(key string, delta int, now time.Time, expire time.Time) {
+	m.counts[key] = &memoryCount{count: max(m.get(key, now)+delta, 0), expire: expire}
+}
+
+// sweep drops the counters of finished periods. Must be called with mu held.
+func (m *MemoryCounter) sweep(now time.Time) {
+	m.calls++
+	if m.calls < sweepEvery {
+		return
+	}
+	m.calls = 0
+	for key, c := range m.counts {
+		if !now.Before(c.expire) {
+			delete(m.counts, key)
+		}
+	}
+}
+
+// Consume counts a link for key at now unless it would exceed limits, and returns the counters.
+func (m *MemoryCounter) Consume(ctx context.Context, key string, limits Limits, now time.Time) (Used, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+
+	daily, monthly, dayEnd, monthEnd := periodKeys(key, now)
+	used := Used{Daily: m.get(daily, now), Monthly: m.get(monthly, now)}
+	if (limits.Daily > 0 && used.Daily >= limits.Daily) || (limits.Monthly > 0 && used.Monthly >= limits.Monthly) {
+		return used, false, nil
+	}
+
+	m.add(daily, 1, now, dayEnd)
+	m.add(monthly, 1, now, monthEnd)
+	return Used{Daily: used.Daily + 1, Monthly: used.Monthly + 1}, true, nil
+}
+
+// Refund takes back a link counted at now.
+func (m *MemoryCounter) Refund(ctx context.Context, key string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	daily, monthly, dayEnd, monthEnd := periodKeys(key, now)
+	m.add(daily, -1, now, dayEnd)
+	m.add(monthly, -1, now, monthEnd)
+	return nil
+}
+
+// Get returns the counters of key at now.
+func (m *MemoryCounter) Get(ctx context.Context, key string, now time.Time) (Used, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	daily, monthly, _, _ := periodKeys(key, now)
+	return Used{Daily: m.get(daily, now), Monthly: m.get(monthly, now)}, nil
+}
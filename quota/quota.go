@@ -0,0 +1,131 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan names
+const (
+	PlanAnonymous = "anonymous" // Clients without an account, counted by IP address
+	PlanFree      = "free"      // Default plan of new accounts
+	PlanPro       = "pro"       // Paying accounts
+	PlanUnlimited = "unlimited" // No quota
+)
+
+// Limits is the number of links a client may create per day and per month (0 means unlimited).
+type Limits struct {
+	Daily   int // Links per calendar day (UTC)
+	Monthly int // Links per calendar month (UTC)
+}
+
+// Plans holds the limits of every plan.
+type Plans map[string]Limits
+
+// DefaultPlans are the limits used unless configured otherwise.
+var DefaultPlans = Plans{
+	PlanAnonymous: {Daily: 10, Monthly: 100},
+	PlanFree:      {Daily: 50, Monthly: 1000},
+	PlanPro:       {Daily: 1000, Monthly: 20000},
+	PlanUnlimited: {Daily: 0, Monthly: 0},
+}
+
+// ParsePlans parses plans written as comma-separated "plan=daily/monthly" entries,
+// e.g. "free=50/1000,pro=1000/20000", on top of DefaultPlans.
+func ParsePlans(value string) (Plans, error) {
+	plans := Plans{}
+	for name, limits := range DefaultPlans {
+		plans[name] = limits
+	}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		dailyValue, monthlyValue, ok2 := strings.Cut(spec, "/")
+		daily, err := strconv.Atoi(dailyValue)
+		monthly, err2 := strconv.Atoi(monthlyValue)
+		if !ok || !ok2 || name == "" || err != nil || err2 != nil || daily < 0 || monthly < 0 {
+			return nil, fmt.Errorf("invalid quota plan %q, expected plan=daily/monthly", entry)
+		}
+		plans[name] = Limits{Daily: daily, Monthly: monthly}
+	}
+	return plans, nil
+}
+
+// Used is the number of links created in the current day and month.
+type Used struct {
+	Daily   int // Links created today
+	Monthly int // Links created this month
+}
+
+// Counter counts the links created by every client in the current day and month.
+// Implementations must update both counters atomically so concurrent requests never overdraw a quota.
+type Counter interface {
+	// Consume counts a link for key at now unless it would exceed limits, and returns the counters.
+	Consume(ctx context.Context, key string, limits Limits, now time.Time) (Used, bool, error)
+	// Refund takes back a link counted at now, e.g. because creating it failed.
+	Refund(ctx context.Context, key string, now time.Time) error
+	// Get returns the counters of key at now.
+	Get(ctx context.Context, key string, now time.Time) (Used, error)
+}
+
+// periodKeys returns the keys of the daily and monthly counters of key at now
+// along with the end of both periods, after which the counters are dropped.
+func periodKeys(key string, now time.Time) (daily string, monthly string, dayEnd time.Time, monthEnd time.Time) {
+	now = now.UTC()
+	year, month, day := now.Date()
+	dayEnd = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	monthEnd = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+	return "quota:" + key + ":d:" + now.Format(time.DateOnly), "quota:" + key + ":m:" + now.Format("2006-01"), dayEnd, monthEnd
+}
+
+// Period describes the quota of a single period.
+type Period struct {
+	Limit     *int      `json:"limit"`     // Links allowed in the period (nil means unlimited)
+	Used      int       `json:"used"`      // Links created in the period
+	Remaining *int      `json:"remaining"` // Links left in the period (nil means unlimited)
+	ResetAt   time.Time `json:"reset_at"`  // When the period ends and the counter starts over
+}
+
+// Usage describes the quota of a client.
+type Usage struct {
+	Plan    string `json:"plan"`    // Plan of the client
+	Daily   Period `json:"daily"`   // Quota of the current day (UTC)
+	Monthly Period `json:"monthly"` // Quota of the current month (UTC)
+}
+
+// NewUsage describes the quota of a client of plan with limits and counters used at now.
+func NewUsage(plan string, limits Limits, used Used, now time.Time) Usage {
+	_, _, dayEnd, monthEnd := periodKeys("", now)
+	return Usage{
+		Plan:    plan,
+		Daily:   newPeriod(limits.Daily, used.Daily, dayEnd),
+		Monthly: newPeriod(limits.Monthly, used.Monthly, monthEnd),
+	}
+}
+
+// newPeriod describes a period allowing limit links (0 means unlimited).
+func newPeriod(limit int, used int, resetAt time.Time) Period {
+	period := Period{Used: used, ResetAt: resetAt}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		period.Limit, period.Remaining = &limit, &remaining
+	}
+	return period
+}
+
+// Exhausted reports whether no link may be created, and if so when the quota allows one again.
+func (u Usage) Exhausted() (bool, time.Time) {
+	if u.Monthly.Remaining != nil && *u.Monthly.Remaining == 0 {
+		return true, u.Monthly.ResetAt
+	}
+	if u.Daily.Remaining != nil && *u.Daily.Remaining == 0 {
+		return true, u.Daily.ResetAt
+	}
+	return false, time.Time{}
+}
@@ -0,0 +1,94 @@
+package quota_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"urlshortener/quota"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// testCounter checks the behaviour shared by every Counter
+func testCounter(t *testing.T, counter quota.Counter) {
+	ctx := context.Background()
+	limits := quota.Limits{Daily: 2, Monthly: 3}
+	day := time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC)
+
+	for i := range 2 {
+		used, ok, err := counter.Consume(ctx, "account:1", limits, day)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, quota.Used{Daily: i + 1, Monthly: i + 1}, used)
+	}
+
+	// Daily quota exhausted
+	_, ok, err := counter.Consume(ctx, "account:1", limits, day)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// A refunded link can be created again
+	require.NoError(t, counter.Refund(ctx, "account:1", day))
+	_, ok, err = counter.Consume(ctx, "account:1", limits, day)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	used, err := counter.Get(ctx, "account:1", day)
+	require.NoError(t, err)
+	require.Equal(t, quota.Used{Daily: 2, Monthly: 2}, used)
+
+	// The next day only the monthly counter carries over
+	used, ok, err = counter.Consume(ctx, "account:1", limits, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, quota.Used{Daily: 1, Monthly: 3}, used)
+
+	// Monthly quota exhausted until the next month
+	_, ok, err = counter.Consume(ctx, "account:1", limits, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, ok, err = counter.Consume(ctx, "account:1", limits, day.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestMemoryCounter(t *testing.T) {
+	testCounter(t, quota.NewMemoryCounter())
+}
+
+func TestRedisCounter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	server.SetTime(time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC))
+	testCounter(t, quota.NewRedisCounter(client, time.Second, nil))
+}
+
+func TestParsePlans(t *testing.T) {
+	plans, err := quota.ParsePlans("free=5/10, team=100/2000")
+	require.NoError(t, err)
+	require.Equal(t, quota.Limits{Daily: 5, Monthly: 10}, plans[quota.PlanFree])
+	require.Equal(t, quota.Limits{Daily: 100, Monthly: 2000}, plans["team"])
+	require.Equal(t, quota.DefaultPlans[quota.PlanPro], plans[quota.PlanPro])
+
+	_, err = quota.ParsePlans("free=5")
+	require.Error(t, err)
+}
+
+func TestUsageExhausted(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	usage := quota.NewUsage(quota.PlanFree, quota.Limits{Daily: 2, Monthly: 10}, quota.Used{Daily: 2, Monthly: 5}, now)
+
+	exhausted, until := usage.Exhausted()
+	require.True(t, exhausted)
+	require.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), until)
+	require.Equal(t, 5, *usage.Monthly.Remaining)
+
+	unlimited := quota.NewUsage(quota.PlanUnlimited, quota.Limits{}, quota.Used{Daily: 100}, now)
+	exhausted, _ = unlimited.Exhausted()
+	require.False(t, exhausted)
+	require.Nil(t, unlimited.Daily.Limit)
+}
@@ -0,0 +1,121 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"urlshortener/limiter"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// consumeScript increments the daily (KEYS[1]) and monthly (KEYS[2]) counters
+// unless either would exceed its limit (ARGV[1] and ARGV[2], 0 means unlimited).
+// Counters expire at the end of their period (ARGV[3] and ARGV[4], Unix milliseconds).
+// Returns whether the link was counted and both counters.
+var consumeScript = redis.NewScript(`
+local daily = tonumber(redis.call("GET", KEYS[1]) or "0")
+local monthly = tonumber(redis.call("GET", KEYS[2]) or "0")
+local dailyLimit = tonumber(ARGV[1])
+local monthlyLimit = tonumber(ARGV[2])
+
+if (dailyLimit > 0 and daily >= dailyLimit) or (monthlyLimit > 0 and monthly >= monthlyLimit) then
+	return {0, daily, monthly}
+end
+
+daily = redis.call("INCR", KEYS[1])
+redis.call("PEXPIREAT", KEYS[1], ARGV[3])
+monthly = redis.call("INCR", KEYS[2])
+redis.call("PEXPIREAT", KEYS[2], ARGV[4])
+return {1, daily, monthly}
+`)
+
+// refundScript decrements the daily (KEYS[1]) and monthly (KEYS[2]) counters if they exist.
+var refundScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if redis.call("EXISTS", key) == 1 and tonumber(redis.call("GET", key)) > 0 then
+		redis.call("DECR", key)
+	end
+end
+return 0
+`)
+
+// RedisCounter implements Counter in Redis, so every instance shares the counters.
+type RedisCounter struct {
+	redis   *redis.Client   // Redis client instance
+	timeout time.Duration   // Maximum duration of a single Redis call (0 means no extra timeout)
+	breaker limiter.Breaker // Fails fast while Redis is down (nil to always call Redis)
+}
+
+// NewRedisCounter creates a new RedisCounter with the given Redis client, per-call timeout and breaker.
+func NewRedisCounter(redis *redis.Client, timeout time.Duration, breaker limiter.Breaker) *RedisCounter {
+	return &RedisCounter{
+		redis:   redis,
+		timeout: timeout,
+		breaker: breaker,
+	}
+}
+
+// do runs call behind the breaker with the per-call timeout.
+func (r *RedisCounter) do(ctx context.Context, call func(ctx context.Context) error) error {
+	bounded := func(ctx context.Context) error {
+		if r.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+		}
+		return call(ctx)
+	}
+	if r.breaker == nil {
+		return bounded(ctx)
+	}
+	return r.breaker.Do(ctx, bounded)
+}
+
+// Consume counts a link for key at now unless it would exceed limits, and returns the counters.
+func (r *RedisCounter) Consume(ctx context.Context, key string, limits Limits, now time.Time) (Used, bool, error) {
+	daily, monthly, dayEnd, monthEnd := periodKeys(key, now)
+	var used Used
+	var counted bool
+	err := r.do(ctx, func(ctx context.Context) error {
+		reply, err := consumeScript.Run(ctx, r.redis, []string{daily, monthly},
+			limits.Daily, limits.Monthly, dayEnd.UnixMilli(), monthEnd.UnixMilli()).Int64Slice()
+		if err != nil {
+			return err
+		}
+		counted = reply[0] == 1
+		used = Used{Daily: int(reply[1]), Monthly: int(reply[2])}
+		return nil
+	})
+	return used, counted, err
+}
+
+// Refund takes back a link counted at now.
+func (r *RedisCounter) Refund(ctx context.Context, key string, now time.Time) error {
+	daily, monthly, _, _ := periodKeys(key, now)
+	return r.do(ctx, func(ctx context.Context) error {
+		return refundScript.Run(ctx, r.redis, []string{daily, monthly}).Err()
+	})
+}
+
+// Get returns the counters of key at now.
+func (r *RedisCounter) Get(ctx context.Context, key string, now time.Time) (Used, error) {
+	daily, monthly, _, _ := periodKeys(key, now)
+	var used Used
+	err := r.do(ctx, func(ctx context.Context) error {
+		values, err := r.redis.MGet(ctx, daily, monthly).Result()
+		if err != nil {
+			return err
+		}
+		used = Used{Daily: toInt(values[0]), Monthly: toInt(values[1])}
+		return nil
+	})
+	return used, err
+}
+
+// toInt converts a counter returned by MGET (nil if missing) to an int.
+func toInt(value any) int {
+	text, _ := value.(string)
+	number, _ := strconv.Atoi(text)
+	return number
+}
@@ -5,16 +5,16 @@ import (
 	"log/slog"
 	"net"
 	"os"
-	"urlshortener/utils"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 // NewRedisClient creates and returns a new Redis client connected to the specified database number.
 // It reads the host and port from environment variables REDIS_HOST and REDIS_PORT.
-// The function pings the Redis server to ensure connectivity and flushes all data before returning the client.
-// Returns the Redis client or an error if connection or flush fails.
-func NewRedisClient(databaseNo int) (*redis.Client, error) {
+// The client connects lazily: if the server cannot be pinged at startup a warning is logged and the
+// client is still returned, reconnecting on its own once Redis comes back.
+func NewRedisClient(databaseNo int) *redis.Client {
 
 	host := os.Getenv("REDIS_HOST")
 	port := os.Getenv("REDIS_PORT")
@@ -23,12 +23,17 @@ func NewRedisClient(databaseNo int) (*redis.Client, error) {
 		Addr:     net.JoinHostPort(host, port), // Redis server address
 		Password: "",                           // No password set
 		DB:       databaseNo,                   // Database number
+
+		// Apply context deadlines to socket reads and writes, otherwise the per-call timeouts of
+		// cache.RedisCache and quota.RedisCounter only take effect after the 3s default ReadTimeout
+		ContextTimeoutEnabled: true,
 	})
 
 	// Ping the Redis server to check connectivity
-	if _, err := client.Ping(context.Background()).Result(); err != nil {
-		slog.Error(" [redis.go] [PING REDIS] ", slog.Any("error", err))
-		return nil, utils.ErrDatabaseConnection
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		slog.Warn(" [redis.go] [PING REDIS] Redis unavailable, continuing without cache ", slog.Any("error", err))
 	}
 
 	// // Flush all data from the Redis server
@@ -37,5 +42,5 @@ func NewRedisClient(databaseNo int) (*redis.Client, error) {
 	// 	return nil, utils.ErrDatabaseDelete
 	// }
 
-	return client, nil
+	return client
 }
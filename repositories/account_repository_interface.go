@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"urlshortener/models"
+)
+
+// AccountRepository defines the interface for account and API key persistence.
+type AccountRepository interface {
+	// Create stores a new account and returns its id.
+	Create(ctx context.Context, account models.Account) (int, error)
+	// GetById retrieves an account by its id.
+	GetById(ctx context.Context, id int) (*models.Account, error)
+	// GetByApiKeyHash retrieves the account owning an API key by the SHA-256 hash of the key,
+	// along with the id of the key.
+	GetByApiKeyHash(ctx context.Context, keyHash string) (*models.Account, int, error)
+	// CreateApiKey stores the SHA-256 hash of a new API key of an account and returns its id.
+	CreateApiKey(ctx context.Context, accountId int, keyHash string) (int, error)
+	// UpdateQuota changes the plan and quota overrides of an account.
+	UpdateQuota(ctx context.Context, account models.Account) error
+}
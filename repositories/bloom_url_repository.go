@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"urlshortener/bloom"
+	"urlshortener/cache"
+	"urlshortener/models"
+)
+
+// ShortCodeLister is implemented by repositories that can enumerate every stored short code
+type ShortCodeLister interface {
+	// ForEachShortCode calls fn with every stored short code
+	ForEachShortCode(ctx context.Context, fn func(shortCode string) error) error
+}
+
+// BloomUrlRepository is a URL repository that keeps a Bloom filter of every existing short code
+// in front of another repository, so most unknown codes are rejected without touching Redis or MySQL
+// Until the first Rebuild completes every lookup is passed through
+// Codes created on other instances are received through an Invalidator channel,
+// and periodic rebuilds repair any message lost in between
+type BloomUrlRepository struct {
+	repo          UrlRepository     // Wrapped repository (e.g., RedisMysqlUrlRepository)
+	bus           cache.Invalidator // Broadcasts created codes to other instances (nil for a single instance)
+	expectedItems int               // Number of codes the filter is sized for
+	fpRate        float64           // Target false-positive rate of the filter
+
+	mu         sync.RWMutex  // Guards filter and rebuilding
+	filter     *bloom.Filter // Filter used for lookups (nil until the first rebuild)
+	rebuilding *bloom.Filter // Filter being rebuilt, also receives new codes while the rebuild runs
+
+	rejected atomic.Uint64 // Lookups rejected by the filter
+}
+
+// NewBloomUrlRepository creates a new BloomUrlRepository in front of repo
+// sized for expectedItems codes at the false-positive rate fpRate (e.g., 0.01)
+func NewBloomUrlRepository(repo UrlRepository, bus cache.Invalidator, expectedItems int, fpRate float64) *BloomUrlRepository {
+	return &BloomUrlRepository{
+		repo:          repo,
+		bus:           bus,
+		expectedItems: expectedItems,
+		fpRate:        fpRate,
+	}
+}
+
+// Rebuild builds a fresh filter from every short code returned by source and swaps it in
+// Codes added while the rebuild runs are recorded in both the old and the new filter
+func (b *BloomUrlRepository) Rebuild(ctx context.Context, source ShortCodeLister) error {
+	next := bloom.New(b.expectedItems, b.fpRate)
+
+	b.mu.Lock()
+	b.rebuilding = next
+	b.mu.Unlock()
+
+	err := source.ForEachShortCode(ctx, func(shortCode string) error {
+		next.Add(shortCode)
+		return nil
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rebuilding = nil
+	if err != nil {
+		slog.Error(" [bloom_url_repository.go] [REBUILD] ", slog.Any("error", err))
+		return err
+	}
+	b.filter = next
+	return nil
+}
+
+// RebuildEvery rebuilds the filter from source every interval until ctx is cancelled
+func (b *BloomUrlRepository) RebuildEvery(ctx context.Context, source ShortCodeLister, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Rebuild(ctx, source)
+		}
+	}
+}
+
+// Listen adds codes created on other instances to the filter until ctx is cancelled
+func (b *BloomUrlRepository) Listen(ctx context.Context) {
+	if b.bus == nil {
+		return
+	}
+	b.bus.Subscribe(ctx, b.add)
+}
+
+// add records a short code in the current filter and in the one being rebuilt
+func (b *BloomUrlRepository) add(shortCode string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.filter != nil {
+		b.filter.Add(shortCode)
+	}
+	if b.rebuilding != nil {
+		b.rebuilding.Add(shortCode)
+	}
+}
+
+// BloomStats describes a BloomUrlRepository
+type BloomStats struct {
+	Ready    bool         `json:"ready"`            // Whether the filter has been built and is rejecting codes
+	Rejected uint64       `json:"rejected"`         // Lookups rejected by the filter
+	Filter   *bloom.Stats `json:"filter,omitempty"` // Size and estimated false-positive rate of the filter
+}
+
+// Stats returns the size and estimated false-positive rate of the filter
+// along with the number of lookups it rejected
+func (b *BloomUrlRepository) Stats() BloomStats {
+	b.mu.RLock()
+	filter := b.filter
+	b.mu.RUnlock()
+
+	stats := BloomStats{
+		Ready:    filter != nil,
+		Rejected: b.rejected.Load(),
+	}
+	if filter != nil {
+		filterStats := filter.Stats()
+		stats.Filter = &filterStats
+	}
+	return stats
+}
+
+// Create stores a new URL mapping and adds its short code to the filter of every instance
+func (b *BloomUrlRepository) Create(ctx context.Context, url models.Url) error {
+	// Add before storing so a concurrent lookup never sees the stored code rejected,
+	// and again after storing in case a rebuild swapped the filter in between
+	b.add(url.Key())
+	if err := b.repo.Create(ctx, url); err != nil {
+		return err
+	}
+	b.add(url.Key())
+	if b.bus != nil {
+		if err := b.bus.Publish(ctx, url.Key()); err != nil {
+			// Other instances reject the code until their next rebuild
+			slog.Warn(" [bloom_url_repository.go] [PUBLISH CODE] ", slog.String("shortCode", url.Key()), slog.Any("error", err))
+		}
+	}
+	return nil
+}
+
+// GetByShortCode returns nil without touching the wrapped repository if the filter
+// knows the short code does not exist, otherwise it delegates the lookup
+func (b *BloomUrlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	b.mu.RLock()
+	filter := b.filter
+	b.mu.RUnlock()
+
+	if filter != nil && !filter.MayContain(shortCode) {
+		b.rejected.Add(1)
+		return nil, nil
+	}
+	return b.repo.GetByShortCode(ctx, shortCode)
+}
+
+// Update changes a URL mapping in the wrapped repository
+func (b *BloomUrlRepository) Update(ctx context.Context, url models.Url) error {
+	return b.repo.Update(ctx, url)
+}
+
+// Delete removes a URL mapping from the wrapped repository
+// Bloom filters cannot forget items, so the code keeps reaching the wrapped repository (and its negative cache)
+func (b *BloomUrlRepository) Delete(ctx context.Context, shortCode string) error {
+	return b.repo.Delete(ctx, shortCode)
+}
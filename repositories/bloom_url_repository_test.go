@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"urlshortener/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// staticShortCodes is a ShortCodeLister returning a fixed set of codes
+type staticShortCodes []string
+
+func (s staticShortCodes) ForEachShortCode(ctx context.Context, fn func(shortCode string) error) error {
+	for _, shortCode := range s {
+		if err := fn(shortCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createUrlRepository is a slowUrlRepository that also accepts new URLs
+type createUrlRepository struct {
+	slowUrlRepository
+}
+
+func (c *createUrlRepository) Create(ctx context.Context, url models.Url) error {
+	return nil
+}
+
+// TestBloomUrlRepositoryRejectsUnknownCodes checks that unknown codes never reach the wrapped repository
+func TestBloomUrlRepositoryRejectsUnknownCodes(t *testing.T) {
+	backend := &createUrlRepository{}
+	repo := NewBloomUrlRepository(backend, nil, 1000, 0.001)
+
+	// Lookups pass through until the filter is built
+	_, err := repo.GetByShortCode(context.Background(), "before")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), backend.lookups.Load())
+
+	require.NoError(t, repo.Rebuild(context.Background(), staticShortCodes{"abc123"}))
+
+	url, err := repo.GetByShortCode(context.Background(), "abc123")
+	require.NoError(t, err)
+	require.NotNil(t, url)
+
+	url, err = repo.GetByShortCode(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Nil(t, url)
+	require.Equal(t, int64(2), backend.lookups.Load())
+	require.Equal(t, uint64(1), repo.Stats().Rejected)
+
+	// Created codes are accepted right away
+	require.NoError(t, repo.Create(context.Background(), models.Url{ShortURL: "new456"}))
+	url, err = repo.GetByShortCode(context.Background(), "new456")
+	require.NoError(t, err)
+	require.NotNil(t, url)
+}
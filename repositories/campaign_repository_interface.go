@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"urlshortener/models"
+)
+
+// CampaignRepository defines the interface for campaign persistence.
+type CampaignRepository interface {
+	// Create stores a new campaign and returns its id.
+	Create(ctx context.Context, campaign models.Campaign) (int, error)
+	// GetById retrieves a campaign by its id.
+	GetById(ctx context.Context, id int) (*models.Campaign, error)
+}
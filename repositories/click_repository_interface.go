@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"urlshortener/models"
+)
+
+// ClickRepository defines the interface for click analytics persistence.
+type ClickRepository interface {
+	// CreateBatch stores several clicks at once.
+	CreateBatch(ctx context.Context, clicks []models.Click) error
+	// Stats summarizes the clicks of a short code.
+	Stats(ctx context.Context, shortCode string) (*models.ClickStats, error)
+	// VariantClicks counts the clicks of a short code by split variant.
+	VariantClicks(ctx context.Context, shortCode string) (map[string]int, error)
+	// CampaignStats summarizes the clicks of every link of a campaign.
+	CampaignStats(ctx context.Context, campaignId int) (*models.CampaignStats, error)
+	// WorkspaceStats summarizes the clicks of every link of a workspace.
+	WorkspaceStats(ctx context.Context, workspaceId int) (*models.WorkspaceStats, error)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"urlshortener/models"
+)
+
+// DomainRepository defines the interface for custom domain persistence.
+type DomainRepository interface {
+	// Create stores a new domain and returns its id.
+	// Several accounts may claim the same host until one of them verifies it.
+	Create(ctx context.Context, domain models.Domain) (int, error)
+	// GetById retrieves a domain by its id.
+	GetById(ctx context.Context, id int) (*models.Domain, error)
+	// ListByHost returns every claim of a host name, the verified one first.
+	ListByHost(ctx context.Context, host string) ([]models.Domain, error)
+	// ListByAccount returns the domains of an account, oldest first.
+	ListByAccount(ctx context.Context, accountId int) ([]models.Domain, error)
+	// ListVerifiedHosts returns the host names of every verified domain.
+	ListVerifiedHosts(ctx context.Context) ([]string, error)
+	// MarkVerified records that ownership of a domain was verified at the given time
+	// and drops the unverified claims of the same host by other accounts.
+	MarkVerified(ctx context.Context, id int, at time.Time) error
+}
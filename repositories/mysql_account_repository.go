@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MysqlAccountRepository implements AccountRepository using a MySQL database as the backend
+// API keys are never stored, only their SHA-256 hash
+type MysqlAccountRepository struct {
+	db *sql.DB // Database connection
+}
+
+// NewMysqlAccountRepository creates a new MysqlAccountRepository with the given database connection
+func NewMysqlAccountRepository(db *sql.DB) *MysqlAccountRepository {
+	return &MysqlAccountRepository{
+		db: db,
+	}
+}
+
+// accountColumns are the columns scanned by scanAccount
+const accountColumns = "a.id, a.email, a.plan, a.daily_quota, a.monthly_quota, a.admin, a.created_at"
+
+// scanAccount reads an account selected with accountColumns (followed by extra destinations)
+func scanAccount(row *sql.Row, extra ...any) (*models.Account, error) {
+	var account models.Account
+	var daily, monthly sql.NullInt64
+	dest := append([]any{&account.Id, &account.Email, &account.Plan, &daily, &monthly, &account.Admin, &account.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	account.DailyQuota = nullIntPtr(daily)
+	account.MonthlyQuota = nullIntPtr(monthly)
+	return &account, nil
+}
+
+// nullIntPtr converts a nullable column to a pointer (nil for NULL)
+func nullIntPtr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	number := int(value.Int64)
+	return &number
+}
+
+// Create inserts a new account into the MySQL database
+// Returns utils.ErrAccountAlreadyExists if the email is taken
+func (a *MysqlAccountRepository) Create(ctx context.Context, account models.Account) (int, error) {
+	query := "INSERT INTO accounts (email, plan, daily_quota, monthly_quota, admin, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	result, err := a.db.ExecContext(ctx, query, account.Email, account.Plan, account.DailyQuota, account.MonthlyQuota, account.Admin, account.CreatedAt)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return 0, utils.ErrAccountAlreadyExists // Duplicate email
+		}
+		slog.Error(" [mysql_account_repository.go] [ACCOUNT INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_account_repository.go] [ACCOUNT ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// GetById retrieves an account by its id from the MySQL database
+func (a *MysqlAccountRepository) GetById(ctx context.Context, id int) (*models.Account, error) {
+	query := "SELECT " + accountColumns + " FROM accounts a WHERE a.id = ?"
+	account, err := scanAccount(a.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_account_repository.go] [ACCOUNT QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return account, nil
+}
+
+// GetByApiKeyHash retrieves the account owning an API key by the hash of the key from the MySQL database
+func (a *MysqlAccountRepository) GetByApiKeyHash(ctx context.Context, keyHash string) (*models.Account, int, error) {
+	query := "SELECT " + accountColumns + ", k.id FROM api_keys k JOIN accounts a ON a.id = k.account_id WHERE k.key_hash = ?"
+	var keyId int
+	account, err := scanAccount(a.db.QueryRowContext(ctx, query, keyHash), &keyId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, nil // No result found
+		}
+		slog.Error(" [mysql_account_repository.go] [API KEY QUERY] ", slog.Any("error", err))
+		return nil, 0, utils.ErrDatabaseQuery
+	}
+	return account, keyId, nil
+}
+
+// CreateApiKey inserts the hash of a new API key of an account into the MySQL database
+func (a *MysqlAccountRepository) CreateApiKey(ctx context.Context, accountId int, keyHash string) (int, error) {
+	query := "INSERT INTO api_keys (account_id, key_hash, created_at) VALUES (?, ?, ?)"
+	result, err := a.db.ExecContext(ctx, query, accountId, keyHash, time.Now())
+	if err != nil {
+		slog.Error(" [mysql_account_repository.go] [API KEY INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_account_repository.go] [API KEY ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// UpdateQuota changes the plan and quota overrides of an account in the MySQL database
+func (a *MysqlAccountRepository) UpdateQuota(ctx context.Context, account models.Account) error {
+	query := "UPDATE accounts SET plan = ?, daily_quota = ?, monthly_quota = ? WHERE id = ?"
+	_, err := a.db.ExecContext(ctx, query, account.Plan, account.DailyQuota, account.MonthlyQuota, account.Id)
+	if err != nil {
+		slog.Error(" [mysql_account_repository.go] [ACCOUNT UPDATE] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	return nil
+}
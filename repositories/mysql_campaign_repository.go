@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MysqlCampaignRepository implements CampaignRepository using a MySQL database as the backend
+type MysqlCampaignRepository struct {
+	db *sql.DB // Database connection
+}
+
+// NewMysqlCampaignRepository creates a new MysqlCampaignRepository with the given database connection
+func NewMysqlCampaignRepository(db *sql.DB) *MysqlCampaignRepository {
+	return &MysqlCampaignRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new campaign into the MySQL database
+// Returns utils.ErrCampaignAlreadyExists if the account already has a campaign with the same name
+func (c *MysqlCampaignRepository) Create(ctx context.Context, campaign models.Campaign) (int, error) {
+	query := "INSERT INTO campaigns (account_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	utm := campaign.UTM
+	result, err := c.db.ExecContext(ctx, query, campaign.AccountId, campaign.Name, utm.Source, utm.Medium, utm.Campaign, utm.Term, utm.Content, campaign.CreatedAt)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return 0, utils.ErrCampaignAlreadyExists // Duplicate name
+		}
+		slog.Error(" [mysql_campaign_repository.go] [CAMPAIGN INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_campaign_repository.go] [CAMPAIGN ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// GetById retrieves a campaign by its id from the MySQL database
+func (c *MysqlCampaignRepository) GetById(ctx context.Context, id int) (*models.Campaign, error) {
+	query := "SELECT id, account_id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at FROM campaigns WHERE id = ?"
+	var campaign models.Campaign
+	utm := &campaign.UTM
+	err := c.db.QueryRowContext(ctx, query, id).Scan(&campaign.Id, &campaign.AccountId, &campaign.Name, &utm.Source, &utm.Medium, &utm.Campaign, &utm.Term, &utm.Content, &campaign.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_campaign_repository.go] [CAMPAIGN QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return &campaign, nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+	"urlshortener/utils"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// MysqlCertCache implements autocert.Cache using a MySQL database as the backend
+// It lets every instance share the ACME account key and certificates
+type MysqlCertCache struct {
+	db *sql.DB // Database connection
+}
+
+// NewMysqlCertCache creates a new MysqlCertCache with the given database connection
+func NewMysqlCertCache(db *sql.DB) *MysqlCertCache {
+	return &MysqlCertCache{
+		db: db,
+	}
+}
+
+// Get retrieves a cached certificate or key by name from the MySQL database
+// Returns autocert.ErrCacheMiss if there is none
+func (c *MysqlCertCache) Get(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := c.db.QueryRowContext(ctx, "SELECT data FROM acme_cache WHERE name = ?", name).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, autocert.ErrCacheMiss
+		}
+		slog.Error(" [mysql_cert_cache.go] [CERT QUERY] ", slog.String("name", name), slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return data, nil
+}
+
+// Put stores a certificate or key in the MySQL database, replacing any previous one with the same name
+func (c *MysqlCertCache) Put(ctx context.Context, name string, data []byte) error {
+	query := "INSERT INTO acme_cache (name, data, updated_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data), updated_at = VALUES(updated_at)"
+	if _, err := c.db.ExecContext(ctx, query, name, data, time.Now()); err != nil {
+		slog.Error(" [mysql_cert_cache.go] [CERT INSERT] ", slog.String("name", name), slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
+	return nil
+}
+
+// Delete removes a certificate or key from the MySQL database
+func (c *MysqlCertCache) Delete(ctx context.Context, name string) error {
+	if _, err := c.db.ExecContext(ctx, "DELETE FROM acme_cache WHERE name = ?", name); err != nil {
+		slog.Error(" [mysql_cert_cache.go] [CERT DELETE] ", slog.String("name", name), slog.Any("error", err))
+		return utils.ErrDatabaseDelete
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"urlshortener/models"
+	"urlshortener/utils"
+)
+
+// MysqlClickRepository implements ClickRepository using a MySQL database as the backend
+type MysqlClickRepository struct {
+	db *sql.DB // Database connection
+}
+
+// NewMysqlClickRepository creates a new MysqlClickRepository with the given database connection
+func NewMysqlClickRepository(db *sql.DB) *MysqlClickRepository {
+	return &MysqlClickRepository{
+		db: db,
+	}
+}
+
+// CreateBatch inserts several clicks with a single statement
+func (c *MysqlClickRepository) CreateBatch(ctx context.Context, clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(clicks))
+	args := make([]any, 0, len(clicks)*9)
+	for i, click := range clicks {
+		placeholders[i] = "(?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		args = append(args, click.ShortURL, click.ClickedAt, click.Destination, click.Country, click.OS, click.Device, click.Browser, click.Referrer, click.Variant)
+	}
+	query := "INSERT INTO clicks (short_url, clicked_at, destination, country, os, device, browser, referrer, variant) VALUES " + strings.Join(placeholders, ", ")
+	if _, err := c.db.ExecContext(ctx, query, args...); err != nil {
+		slog.Error(" [mysql_click_repository.go] [CLICK INSERT] ", slog.Int("clicks", len(clicks)), slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
+	return nil
+}
+
+// Stats counts the clicks of a short code, in total and grouped by each dimension
+func (c *MysqlClickRepository) Stats(ctx context.Context, shortCode string) (*models.ClickStats, error) {
+	stats := &models.ClickStats{ShortURL: shortCode}
+	dimensions := []struct {
+		column string
+		counts *map[string]int
+	}{
+		{"country", &stats.Countries},
+		{"os", &stats.OS},
+		{"device", &stats.Devices},
+		{"browser", &stats.Browsers},
+		{"referrer", &stats.Referrers},
+	}
+
+	for _, dimension := range dimensions {
+		counts, err := c.countBy(ctx, dimension.column, shortCode)
+		if err != nil {
+			return nil, err
+		}
+		*dimension.counts = counts
+	}
+	for _, count := range stats.Countries {
+		stats.Total += count
+	}
+	return stats, nil
+}
+
+// VariantClicks counts the clicks of a short code by split variant, leaving out clicks without a variant
+func (c *MysqlClickRepository) VariantClicks(ctx context.Context, shortCode string) (map[string]int, error) {
+	counts, err := c.countBy(ctx, "variant", shortCode)
+	if err != nil {
+		return nil, err
+	}
+	delete(counts, "")
+	return counts, nil
+}
+
+// CampaignStats counts the links of a campaign and their clicks, in total, by link, by country and by device class
+func (c *MysqlClickRepository) CampaignStats(ctx context.Context, campaignId int) (*models.CampaignStats, error) {
+	stats := &models.CampaignStats{CampaignId: campaignId}
+	var err error
+	if stats.ByLink, stats.Countries, stats.Devices, err = c.groupStats(ctx, "campaign_id", campaignId); err != nil {
+		return nil, err
+	}
+	stats.Links = len(stats.ByLink)
+	for _, clicks := range stats.ByLink {
+		stats.Total += clicks
+	}
+	return stats, nil
+}
+
+// WorkspaceStats counts the links of a workspace and their clicks, in total, by link, by country and by device class
+func (c *MysqlClickRepository) WorkspaceStats(ctx context.Context, workspaceId int) (*models.WorkspaceStats, error) {
+	stats := &models.WorkspaceStats{WorkspaceId: workspaceId}
+	var err error
+	if stats.ByLink, stats.Countries, stats.Devices, err = c.groupStats(ctx, "workspace_id", workspaceId); err != nil {
+		return nil, err
+	}
+	stats.Links = len(stats.ByLink)
+	for _, clicks := range stats.ByLink {
+		stats.Total += clicks
+	}
+	return stats, nil
+}
+
+// groupStats counts the clicks of the links whose owner column (a trusted column name of urls) equals id,
+// by link key, by country and by device class
+// Links without clicks are counted with 0
+func (c *MysqlClickRepository) groupStats(ctx context.Context, column string, id int) (byLink, countries, devices map[string]int, err error) {
+	byLink, err = c.count(ctx, "SELECT "+urlKeyColumn+", COUNT(c.id) FROM urls LEFT JOIN clicks c ON c.short_url = "+urlKeyColumn+" WHERE urls."+column+" = ? GROUP BY urls.domain, urls.short_url", id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if countries, err = c.count(ctx, "SELECT c.country, COUNT(*) FROM clicks c JOIN urls ON c.short_url = "+urlKeyColumn+" WHERE urls."+column+" = ? GROUP BY c.country", id); err != nil {
+		return nil, nil, nil, err
+	}
+	if devices, err = c.count(ctx, "SELECT c.device, COUNT(*) FROM clicks c JOIN urls ON c.short_url = "+urlKeyColumn+" WHERE urls."+column+" = ? GROUP BY c.device", id); err != nil {
+		return nil, nil, nil, err
+	}
+	return byLink, countries, devices, nil
+}
+
+// countBy counts the clicks of a short code grouped by column, which must be a trusted column name
+func (c *MysqlClickRepository) countBy(ctx context.Context, column string, shortCode string) (map[string]int, error) {
+	return c.count(ctx, "SELECT "+column+", COUNT(*) FROM clicks WHERE short_url = ? GROUP BY "+column, shortCode)
+}
+
+// count runs a query selecting a value and a count per row and returns the counts by value
+func (c *MysqlClickRepository) count(ctx context.Context, query string, args ...any) (map[string]int, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(" [mysql_click_repository.go] [CLICK STATS] ", slog.String("query", query), slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var value string
+		var count int
+		if err := rows.Scan(&value, &count); err != nil {
+			slog.Error(" [mysql_click_repository.go] [CLICK STATS] ", slog.String("query", query), slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		counts[value] = count
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_click_repository.go] [CLICK STATS] ", slog.String("query", query), slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return counts, nil
+}
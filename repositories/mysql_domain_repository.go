@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MysqlDomainRepository implements DomainRepository using a MySQL database as the backend
+type MysqlDomainRepository struct {
+	db *sql.DB // Database connection
+}
+
+// domainColumns are the columns scanned by scanDomain
+const domainColumns = "id, account_id, host, verification_token, verified_at, created_at"
+
+// scanDomain reads a domain selected with domainColumns
+func scanDomain(row rowScanner) (*models.Domain, error) {
+	var domain models.Domain
+	var verifiedAt sql.NullTime
+	if err := row.Scan(&domain.Id, &domain.AccountId, &domain.Host, &domain.VerificationToken, &verifiedAt, &domain.CreatedAt); err != nil {
+		return nil, err
+	}
+	if verifiedAt.Valid {
+		domain.VerifiedAt = &verifiedAt.Time
+	}
+	return &domain, nil
+}
+
+// NewMysqlDomainRepository creates a new MysqlDomainRepository with the given database connection
+func NewMysqlDomainRepository(db *sql.DB) *MysqlDomainRepository {
+	return &MysqlDomainRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new domain into the MySQL database
+// Returns utils.ErrDomainAlreadyExists if the account has already added the host
+func (d *MysqlDomainRepository) Create(ctx context.Context, domain models.Domain) (int, error) {
+	query := "INSERT INTO domains (account_id, host, verification_token, created_at) VALUES (?, ?, ?, ?)"
+	result, err := d.db.ExecContext(ctx, query, domain.AccountId, domain.Host, domain.VerificationToken, domain.CreatedAt)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return 0, utils.ErrDomainAlreadyExists // Host already added by the account
+		}
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// GetById retrieves a domain by its id from the MySQL database
+func (d *MysqlDomainRepository) GetById(ctx context.Context, id int) (*models.Domain, error) {
+	domain, err := scanDomain(d.db.QueryRowContext(ctx, "SELECT "+domainColumns+" FROM domains WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return domain, nil
+}
+
+// ListByAccount returns the domains of an account from the MySQL database, oldest first
+func (d *MysqlDomainRepository) ListByAccount(ctx context.Context, accountId int) ([]models.Domain, error) {
+	return d.list(ctx, "account_id = ? ORDER BY id", accountId)
+}
+
+// ListByHost returns every claim of a host name from the MySQL database, the verified one first, then oldest first
+func (d *MysqlDomainRepository) ListByHost(ctx context.Context, host string) ([]models.Domain, error) {
+	return d.list(ctx, "host = ? ORDER BY verified_at IS NULL, id", host)
+}
+
+// list returns the domains matching a trusted WHERE condition
+func (d *MysqlDomainRepository) list(ctx context.Context, condition string, arg any) ([]models.Domain, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT "+domainColumns+" FROM domains WHERE "+condition, arg)
+	if err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN LIST] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	domains := []models.Domain{}
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			slog.Error(" [mysql_domain_repository.go] [DOMAIN SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		domains = append(domains, *domain)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return domains, nil
+}
+
+// ListVerifiedHosts returns the host names of every verified domain from the MySQL database
+func (d *MysqlDomainRepository) ListVerifiedHosts(ctx context.Context) ([]string, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT host FROM domains WHERE verified_at IS NOT NULL")
+	if err != nil {
+		slog.Error(" [mysql_domain_repository.go] [VERIFIED HOSTS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			slog.Error(" [mysql_domain_repository.go] [VERIFIED HOSTS SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		hosts = append(hosts, host)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_domain_repository.go] [VERIFIED HOSTS ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return hosts, nil
+}
+
+// MarkVerified records the time ownership of a domain was verified in the MySQL database
+// and deletes the unverified claims of the same host by other accounts, in a single transaction
+// Returns utils.ErrDomainNotFound if no row matches the id
+// and utils.ErrDomainAlreadyExists if another claim of the host was verified first
+func (d *MysqlDomainRepository) MarkVerified(ctx context.Context, id int, at time.Time) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN VERIFY BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	defer tx.Rollback()
+
+	var host string
+	if err := tx.QueryRowContext(ctx, "SELECT host FROM domains WHERE id = ? FOR UPDATE", id).Scan(&host); err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrDomainNotFound // Dropped when another claim was verified
+		}
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN VERIFY LOCK] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	// verified_host is only set on verified rows and is unique, so a second verification of the host fails here
+	if _, err := tx.ExecContext(ctx, "UPDATE domains SET verified_at = ? WHERE id = ?", at, id); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return utils.ErrDomainAlreadyExists
+		}
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN VERIFY] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM domains WHERE host = ? AND verified_at IS NULL", host); err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN VERIFY CLAIMS] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_domain_repository.go] [DOMAIN VERIFY COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	return nil
+}
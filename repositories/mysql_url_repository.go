@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"log/slog"
+	"strings"
+	"time"
 	"urlshortener/models"
 	"urlshortener/utils"
 )
@@ -13,6 +17,77 @@ type MysqlUrlRepository struct {
 	db *sql.DB // Database connection
 }
 
+// urlColumns are the columns scanned by scanUrl, ending with the comma-separated tags of the link
+const urlColumns = "id, url, short_url, domain, created_at, expire, preview, rules, countries, split, forward_query, forward_path, campaign_id, deep_link, workspace_id, created_by, title, notes, folder" +
+	", (SELECT GROUP_CONCAT(t.tag ORDER BY t.tag SEPARATOR ',') FROM url_tags t WHERE t.url_id = urls.id)"
+
+// urlKeyColumn is the SQL expression of the models.LinkKey of a row of urls
+const urlKeyColumn = "IF(urls.domain = '', urls.short_url, CONCAT(urls.domain, '/', urls.short_url))"
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUrl reads a URL mapping selected with urlColumns
+func scanUrl(row rowScanner) (*models.Url, error) {
+	var url models.Url
+	var rules, countries, split, deepLink []byte
+	var campaignId, workspaceId, createdBy sql.NullInt64
+	var notes, tags sql.NullString
+	if err := row.Scan(&url.Id, &url.URL, &url.ShortURL, &url.Domain, &url.CreatedAt, &url.Expire, &url.Preview, &rules, &countries, &split, &url.ForwardQuery, &url.ForwardPath, &campaignId, &deepLink, &workspaceId, &createdBy, &url.Title, &notes, &url.Folder, &tags); err != nil {
+		return nil, err
+	}
+	url.CampaignId = nullIntPtr(campaignId)
+	url.WorkspaceId = nullIntPtr(workspaceId)
+	url.CreatedBy = nullIntPtr(createdBy)
+	url.Notes = notes.String
+	if tags.Valid {
+		url.Tags = strings.Split(tags.String, ",") // Tags never contain commas
+	}
+	for _, column := range []struct {
+		data []byte
+		dest any
+	}{{rules, &url.Rules}, {countries, &url.Countries}, {split, &url.Split}, {deepLink, &url.DeepLink}} {
+		if len(column.data) == 0 {
+			continue // NULL
+		}
+		if err := json.Unmarshal(column.data, column.dest); err != nil {
+			return nil, err
+		}
+	}
+	return &url, nil
+}
+
+// encodeJson converts value to the JSON stored in a JSON column, NULL when empty is true
+func encodeJson(value any, empty bool) (any, error) {
+	if empty {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// encodeTargeting converts the routing rules, country destinations, split and deep link of url to their JSON columns
+func encodeTargeting(url models.Url) (rules any, countries any, split any, deepLink any, err error) {
+	if rules, err = encodeJson(url.Rules, len(url.Rules) == 0); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if countries, err = encodeJson(url.Countries, len(url.Countries) == 0); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if split, err = encodeJson(url.Split, url.Split == nil); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if deepLink, err = encodeJson(url.DeepLink, url.DeepLink == nil); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return rules, countries, split, deepLink, nil
+}
+
 // NewMysqlUrlRepository creates a new MysqlUrlRepository with the given database connection
 func NewMysqlUrlRepository(db *sql.DB) *MysqlUrlRepository {
 	return &MysqlUrlRepository{
@@ -20,23 +95,62 @@ func NewMysqlUrlRepository(db *sql.DB) *MysqlUrlRepository {
 	}
 }
 
-// Create inserts a new URL mapping into the MySQL database
-func (u *MysqlUrlRepository) Create(url models.Url) error {
-	query := "INSERT INTO urls (url, short_url, created_at, expire) VALUES (?, ?, ?, ?)"
-	_, err := u.db.Exec(query, url.URL, url.ShortURL, url.CreatedAt, url.Expire)
+// Create inserts a new URL mapping and its tags into the MySQL database in a single transaction
+func (u *MysqlUrlRepository) Create(ctx context.Context, url models.Url) error {
+	rules, countries, split, deepLink, err := encodeTargeting(url)
+	if err != nil {
+		return utils.ErrDatabaseInsert
+	}
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO urls (url, short_url, domain, created_at, expire, preview, rules, countries, split, forward_query, forward_path, campaign_id, deep_link, workspace_id, created_by, title, notes, folder) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	result, err := tx.ExecContext(ctx, query, url.URL, url.ShortURL, url.Domain, url.CreatedAt, url.Expire, url.Preview, rules, countries, split, url.ForwardQuery, url.ForwardPath, url.CampaignId, deepLink, url.WorkspaceId, url.CreatedBy, url.Title, url.Notes, url.Folder)
 	if err != nil {
 		slog.Error(" [mysql_url_repository.go] [URL INSERT] ", slog.Any("error", err))
 		return utils.ErrDatabaseInsert
 	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL ID] ", slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
+	if err := insertTags(ctx, tx, int(id), url.Tags); err != nil {
+		return utils.ErrDatabaseInsert
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
 	return nil
 }
 
-// GetByShortCode retrieves a URL mapping by its short code from the MySQL database
-func (u *MysqlUrlRepository) GetByShortCode(shortCode string) (*models.Url, error) {
-	query := "SELECT id, url, short_url, created_at, expire FROM urls WHERE short_url = ?"
-	row := u.db.QueryRow(query, shortCode)
-	var url models.Url
-	err := row.Scan(&url.Id, &url.URL, &url.ShortURL, &url.CreatedAt, &url.Expire)
+// insertTags adds tags to the link with id urlId within tx
+func insertTags(ctx context.Context, tx *sql.Tx, urlId int, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 2*len(tags))
+	for _, tag := range tags {
+		args = append(args, urlId, tag)
+	}
+	query := "INSERT INTO url_tags (url_id, tag) VALUES " + strings.Repeat("(?, ?), ", len(tags)-1) + "(?, ?)"
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		slog.Error(" [mysql_url_repository.go] [TAG INSERT] ", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetByShortCode retrieves a URL mapping by its models.LinkKey from the MySQL database
+func (u *MysqlUrlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	domain, code := models.SplitLinkKey(shortCode)
+	query := "SELECT " + urlColumns + " FROM urls WHERE domain = ? AND short_url = ?"
+	url, err := scanUrl(u.db.QueryRowContext(ctx, query, domain, code))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No result found
@@ -44,5 +158,221 @@ func (u *MysqlUrlRepository) GetByShortCode(shortCode string) (*models.Url, erro
 		slog.Error(" [mysql_url_repository.go] [URL QUERY] ", slog.Any("error", err))
 		return nil, utils.ErrDatabaseQuery
 	}
-	return &url, nil
+	return url, nil
+}
+
+// Update changes the original URL, expiration, labels and tags of an existing short code in the MySQL database
+// Returns utils.ErrUrlNotFound if no row matches the short code
+func (u *MysqlUrlRepository) Update(ctx context.Context, url models.Url) error {
+	rules, countries, split, deepLink, err := encodeTargeting(url)
+	if err != nil {
+		return utils.ErrDatabaseUpdate
+	}
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	defer tx.Rollback()
+
+	// Lock the link and look up its id, which its tags reference
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM urls WHERE domain = ? AND short_url = ? FOR UPDATE", url.Domain, url.ShortURL).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrUrlNotFound
+		}
+		slog.Error(" [mysql_url_repository.go] [URL LOCK] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+
+	query := "UPDATE urls SET url = ?, expire = ?, preview = ?, rules = ?, countries = ?, split = ?, forward_query = ?, forward_path = ?, deep_link = ?, title = ?, notes = ?, folder = ? WHERE id = ?"
+	if _, err := tx.ExecContext(ctx, query, url.URL, url.Expire, url.Preview, rules, countries, split, url.ForwardQuery, url.ForwardPath, deepLink, url.Title, url.Notes, url.Folder, id); err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL UPDATE] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM url_tags WHERE url_id = ?", id); err != nil {
+		slog.Error(" [mysql_url_repository.go] [TAG DELETE] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if err := insertTags(ctx, tx, id, url.Tags); err != nil {
+		return utils.ErrDatabaseUpdate
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	return nil
+}
+
+// Delete removes a URL mapping by its models.LinkKey from the MySQL database
+// Returns utils.ErrUrlNotFound if no row matches the short code
+func (u *MysqlUrlRepository) Delete(ctx context.Context, shortCode string) error {
+	domain, code := models.SplitLinkKey(shortCode)
+	query := "DELETE FROM urls WHERE domain = ? AND short_url = ?"
+	result, err := u.db.ExecContext(ctx, query, domain, code)
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [URL DELETE] ", slog.Any("error", err))
+		return utils.ErrDatabaseDelete
+	}
+	return checkRowsAffected(result)
+}
+
+// checkRowsAffected returns utils.ErrUrlNotFound if the statement did not touch any row
+func checkRowsAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [ROWS AFFECTED] ", slog.Any("error", err))
+		return utils.ErrDatabaseQuery
+	}
+	if rows == 0 {
+		return utils.ErrUrlNotFound
+	}
+	return nil
+}
+
+// ForEachShortCode calls fn with the models.LinkKey of every short code stored in the MySQL database
+// Stops and returns the first error returned by fn or by the query
+func (u *MysqlUrlRepository) ForEachShortCode(ctx context.Context, fn func(shortCode string) error) error {
+	rows, err := u.db.QueryContext(ctx, "SELECT "+urlKeyColumn+" FROM urls")
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [SHORT CODE QUERY] ", slog.Any("error", err))
+		return utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shortCode string
+		if err := rows.Scan(&shortCode); err != nil {
+			slog.Error(" [mysql_url_repository.go] [SHORT CODE SCAN] ", slog.Any("error", err))
+			return utils.ErrDatabaseQuery
+		}
+		if err := fn(shortCode); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_url_repository.go] [SHORT CODE ROWS] ", slog.Any("error", err))
+		return utils.ErrDatabaseQuery
+	}
+	return nil
+}
+
+// popularWindow is the period over which clicks are counted by ListPopular
+const popularWindow = 7 * 24 * time.Hour
+
+// ListPopular returns up to limit unexpired URL mappings, most clicked over the last week first,
+// then most recently created first
+// Used to warm the cache at startup
+func (u *MysqlUrlRepository) ListPopular(ctx context.Context, limit int) ([]models.Url, error) {
+	now := time.Now()
+	query := "SELECT " + urlColumns + " FROM urls" +
+		" LEFT JOIN (SELECT short_url AS code, COUNT(*) AS clicks FROM clicks WHERE clicked_at > ? GROUP BY short_url) recent ON recent.code = " + urlKeyColumn +
+		" WHERE expire = created_at OR expire > ? ORDER BY COALESCE(recent.clicks, 0) DESC, created_at DESC LIMIT ?"
+	rows, err := u.db.QueryContext(ctx, query, now.Add(-popularWindow), now, limit)
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [POPULAR QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	var urls []models.Url
+	for rows.Next() {
+		url, err := scanUrl(rows)
+		if err != nil {
+			slog.Error(" [mysql_url_repository.go] [POPULAR SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		urls = append(urls, *url)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_url_repository.go] [POPULAR ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return urls, nil
+}
+
+// Search returns the URL mappings matching filter, newest first
+// now decides which links count as expired
+func (u *MysqlUrlRepository) Search(ctx context.Context, filter models.LinkFilter, now time.Time) ([]models.Url, error) {
+	var where []string
+	var args []any
+	add := func(condition string, values ...any) {
+		where = append(where, condition)
+		args = append(args, values...)
+	}
+
+	if filter.VisibleTo != nil {
+		add("(urls.created_by = ? OR urls.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE account_id = ?))", *filter.VisibleTo, *filter.VisibleTo)
+	}
+	if filter.WorkspaceId != nil {
+		add("urls.workspace_id = ?", *filter.WorkspaceId)
+	}
+	if filter.CreatedBy != nil {
+		add("urls.created_by = ?", *filter.CreatedBy)
+	}
+	if filter.Domain != nil {
+		add("urls.domain = ?", *filter.Domain)
+	}
+	if filter.Folder != nil {
+		add("urls.folder = ?", *filter.Folder)
+	}
+	if filter.CreatedAfter != nil {
+		add("urls.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		add("urls.created_at < ?", *filter.CreatedBefore)
+	}
+	// Links without expiration store their creation time as expire
+	switch filter.Expiry {
+	case models.ExpiryNever:
+		add("urls.expire = urls.created_at")
+	case models.ExpiryActive:
+		add("(urls.expire = urls.created_at OR urls.expire > ?)", now)
+	case models.ExpiryExpired:
+		add("(urls.expire <> urls.created_at AND urls.expire <= ?)", now)
+	}
+	if filter.Query != "" {
+		add("MATCH(urls.url, urls.title) AGAINST (? IN NATURAL LANGUAGE MODE)", filter.Query)
+	}
+	if len(filter.Tags) > 0 {
+		condition := "urls.id IN (SELECT url_id FROM url_tags WHERE tag IN (?" + strings.Repeat(", ?", len(filter.Tags)-1) + ") GROUP BY url_id HAVING COUNT(*) = ?)"
+		values := make([]any, 0, len(filter.Tags)+1)
+		for _, tag := range filter.Tags {
+			values = append(values, tag)
+		}
+		add(condition, append(values, len(filter.Tags))...)
+	}
+	if filter.Before != 0 {
+		add("urls.id < ?", filter.Before)
+	}
+
+	query := "SELECT " + urlColumns + " FROM urls"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY urls.id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := u.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		slog.Error(" [mysql_url_repository.go] [SEARCH QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	urls := []models.Url{}
+	for rows.Next() {
+		url, err := scanUrl(rows)
+		if err != nil {
+			slog.Error(" [mysql_url_repository.go] [SEARCH SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		urls = append(urls, *url)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_url_repository.go] [SEARCH ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return urls, nil
 }
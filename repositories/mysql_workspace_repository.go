@@ -0,0 +1,335 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MysqlWorkspaceRepository implements WorkspaceRepository using a MySQL database as the backend
+type MysqlWorkspaceRepository struct {
+	db *sql.DB // Database connection
+}
+
+// workspaceColumns are the columns scanned by scanWorkspace
+const workspaceColumns = "w.id, w.name, w.plan, w.daily_quota, w.monthly_quota, w.created_at"
+
+// memberColumns are the columns scanned by scanMember, from workspace_members joined with accounts
+const memberColumns = "m.workspace_id, m.account_id, a.email, m.role, m.created_at"
+
+// scanWorkspace reads a workspace selected with workspaceColumns, followed by extra columns
+func scanWorkspace(row rowScanner, extra ...any) (*models.Workspace, error) {
+	var workspace models.Workspace
+	var daily, monthly sql.NullInt64
+	dest := append([]any{&workspace.Id, &workspace.Name, &workspace.Plan, &daily, &monthly, &workspace.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	workspace.DailyQuota = nullIntPtr(daily)
+	workspace.MonthlyQuota = nullIntPtr(monthly)
+	return &workspace, nil
+}
+
+// scanMember reads a member selected with memberColumns
+func scanMember(row rowScanner) (*models.Member, error) {
+	var member models.Member
+	if err := row.Scan(&member.WorkspaceId, &member.AccountId, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// NewMysqlWorkspaceRepository creates a new MysqlWorkspaceRepository with the given database connection
+func NewMysqlWorkspaceRepository(db *sql.DB) *MysqlWorkspaceRepository {
+	return &MysqlWorkspaceRepository{
+		db: db,
+	}
+}
+
+// Create inserts a new workspace and its first member in a single transaction
+func (w *MysqlWorkspaceRepository) Create(ctx context.Context, workspace models.Workspace, owner models.Member) (int, error) {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE BEGIN] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO workspaces (name, plan, daily_quota, monthly_quota, created_at) VALUES (?, ?, ?, ?, ?)"
+	result, err := tx.ExecContext(ctx, query, workspace.Name, workspace.Plan, workspace.DailyQuota, workspace.MonthlyQuota, workspace.CreatedAt)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	owner.WorkspaceId = int(id)
+	if err := insertMember(ctx, tx, owner); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE COMMIT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// insertMember adds an account to a workspace within tx
+// Returns utils.ErrMemberAlreadyExists if the account is already a member
+func insertMember(ctx context.Context, tx *sql.Tx, member models.Member) error {
+	query := "INSERT INTO workspace_members (workspace_id, account_id, role, created_at) VALUES (?, ?, ?, ?)"
+	if _, err := tx.ExecContext(ctx, query, member.WorkspaceId, member.AccountId, member.Role, member.CreatedAt); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return utils.ErrMemberAlreadyExists // Duplicate (workspace_id, account_id)
+		}
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER INSERT] ", slog.Any("error", err))
+		return utils.ErrDatabaseInsert
+	}
+	return nil
+}
+
+// GetById retrieves a workspace by its id from the MySQL database
+func (w *MysqlWorkspaceRepository) GetById(ctx context.Context, id int) (*models.Workspace, error) {
+	workspace, err := scanWorkspace(w.db.QueryRowContext(ctx, "SELECT "+workspaceColumns+" FROM workspaces w WHERE w.id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return workspace, nil
+}
+
+// ListByAccount returns the workspaces an account is a member of from the MySQL database, oldest first
+func (w *MysqlWorkspaceRepository) ListByAccount(ctx context.Context, accountId int) ([]models.Membership, error) {
+	query := "SELECT " + workspaceColumns + ", m.role FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id WHERE m.account_id = ? ORDER BY w.id"
+	rows, err := w.db.QueryContext(ctx, query, accountId)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE LIST] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	memberships := []models.Membership{}
+	for rows.Next() {
+		var role models.Role
+		workspace, err := scanWorkspace(rows, &role)
+		if err != nil {
+			slog.Error(" [mysql_workspace_repository.go] [WORKSPACE SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		memberships = append(memberships, models.Membership{Workspace: *workspace, Role: role})
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [WORKSPACE ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return memberships, nil
+}
+
+// GetMember retrieves the membership of an account in a workspace from the MySQL database
+func (w *MysqlWorkspaceRepository) GetMember(ctx context.Context, workspaceId int, accountId int) (*models.Member, error) {
+	query := "SELECT " + memberColumns + " FROM workspace_members m JOIN accounts a ON a.id = m.account_id WHERE m.workspace_id = ? AND m.account_id = ?"
+	member, err := scanMember(w.db.QueryRowContext(ctx, query, workspaceId, accountId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return member, nil
+}
+
+// ListMembers returns the members of a workspace from the MySQL database, oldest first
+func (w *MysqlWorkspaceRepository) ListMembers(ctx context.Context, workspaceId int) ([]models.Member, error) {
+	query := "SELECT " + memberColumns + " FROM workspace_members m JOIN accounts a ON a.id = m.account_id WHERE m.workspace_id = ? ORDER BY m.created_at, m.account_id"
+	rows, err := w.db.QueryContext(ctx, query, workspaceId)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER LIST] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	members := []models.Member{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			slog.Error(" [mysql_workspace_repository.go] [MEMBER SCAN] ", slog.Any("error", err))
+			return nil, utils.ErrDatabaseQuery
+		}
+		members = append(members, *member)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER ROWS] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	return members, nil
+}
+
+// lockOwners locks the owner rows of a workspace within tx, so concurrent changes can't remove every owner
+// Returns utils.ErrLastOwner if accountId is the only owner
+func lockOwners(ctx context.Context, tx *sql.Tx, workspaceId int, accountId int) error {
+	rows, err := tx.QueryContext(ctx, "SELECT account_id FROM workspace_members WHERE workspace_id = ? AND role = ? FOR UPDATE", workspaceId, models.RoleOwner)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [OWNER LOCK] ", slog.Any("error", err))
+		return utils.ErrDatabaseQuery
+	}
+	defer rows.Close()
+
+	owners, isOwner := 0, false
+	for rows.Next() {
+		var owner int
+		if err := rows.Scan(&owner); err != nil {
+			slog.Error(" [mysql_workspace_repository.go] [OWNER SCAN] ", slog.Any("error", err))
+			return utils.ErrDatabaseQuery
+		}
+		owners++
+		isOwner = isOwner || owner == accountId
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [OWNER ROWS] ", slog.Any("error", err))
+		return utils.ErrDatabaseQuery
+	}
+	if isOwner && owners == 1 {
+		return utils.ErrLastOwner
+	}
+	return nil
+}
+
+// UpdateRole changes the role of a member in the MySQL database
+// The owner rows are locked while the role changes, so the last owner can't be demoted concurrently
+// Returns utils.ErrMemberNotFound if the account is not a member of the workspace
+// and utils.ErrLastOwner if it is the only owner and role isn't owner
+func (w *MysqlWorkspaceRepository) UpdateRole(ctx context.Context, workspaceId int, accountId int, role models.Role) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER UPDATE BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	defer tx.Rollback()
+
+	if role != models.RoleOwner {
+		if err := lockOwners(ctx, tx, workspaceId, accountId); err != nil {
+			return err
+		}
+	}
+	result, err := tx.ExecContext(ctx, "UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND account_id = ?", role, workspaceId, accountId)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER UPDATE] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return utils.ErrMemberNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER UPDATE COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	return nil
+}
+
+// RemoveMember removes an account from a workspace in the MySQL database
+// The owner rows are locked while the member is removed, so the last owner can't be removed concurrently
+// Returns utils.ErrMemberNotFound if the account is not a member of the workspace
+// and utils.ErrLastOwner if it is the only owner
+func (w *MysqlWorkspaceRepository) RemoveMember(ctx context.Context, workspaceId int, accountId int) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER DELETE BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseDelete
+	}
+	defer tx.Rollback()
+
+	if err := lockOwners(ctx, tx, workspaceId, accountId); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM workspace_members WHERE workspace_id = ? AND account_id = ?", workspaceId, accountId)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER DELETE] ", slog.Any("error", err))
+		return utils.ErrDatabaseDelete
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return utils.ErrMemberNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [MEMBER DELETE COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseDelete
+	}
+	return nil
+}
+
+// CreateInvitation inserts a new invitation into the MySQL database
+func (w *MysqlWorkspaceRepository) CreateInvitation(ctx context.Context, invitation models.Invitation) (int, error) {
+	query := "INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	result, err := w.db.ExecContext(ctx, query, invitation.WorkspaceId, invitation.Email, invitation.Role, invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt, invitation.CreatedAt)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION INSERT] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION ID] ", slog.Any("error", err))
+		return 0, utils.ErrDatabaseInsert
+	}
+	return int(id), nil
+}
+
+// GetInvitationByTokenHash retrieves an invitation by the SHA-256 hash of its token from the MySQL database
+func (w *MysqlWorkspaceRepository) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	query := "SELECT id, workspace_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at FROM workspace_invitations WHERE token_hash = ?"
+	var invitation models.Invitation
+	var acceptedAt sql.NullTime
+	err := w.db.QueryRowContext(ctx, query, tokenHash).Scan(&invitation.Id, &invitation.WorkspaceId, &invitation.Email, &invitation.Role, &invitation.TokenHash, &invitation.InvitedBy, &invitation.ExpiresAt, &acceptedAt, &invitation.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No result found
+		}
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION QUERY] ", slog.Any("error", err))
+		return nil, utils.ErrDatabaseQuery
+	}
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation marks an invitation accepted and adds member to its workspace in a single transaction
+// Returns utils.ErrInvitationNotFound if the invitation has already been accepted
+// and utils.ErrMemberAlreadyExists if the account is already a member
+func (w *MysqlWorkspaceRepository) AcceptInvitation(ctx context.Context, invitationId int, member models.Member, at time.Time) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION BEGIN] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE workspace_invitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL", at, invitationId)
+	if err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION ACCEPT] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return utils.ErrInvitationNotFound // Accepted concurrently
+	}
+	if err := insertMember(ctx, tx, member); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error(" [mysql_workspace_repository.go] [INVITATION COMMIT] ", slog.Any("error", err))
+		return utils.ErrDatabaseUpdate
+	}
+	return nil
+}
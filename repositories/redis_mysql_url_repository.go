@@ -1,87 +1,325 @@
 package repositories
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
+	"math/rand/v2"
 	"time"
 	"urlshortener/cache"
 	"urlshortener/models"
 	"urlshortener/utils"
+
+	"golang.org/x/sync/singleflight"
 )
 
+// cacheTTL is the maximum time a URL stays in cache
+const cacheTTL = 5 * time.Minute
+
+// fillTimeout bounds a shared cache fill, which outlives the request that started it
+const fillTimeout = 5 * time.Second
+
+// cachedUrl is the value stored under "short:<code>"
+// ExpireAt and Delta drive probabilistic early refresh (XFetch)
+// NotFound entries cache the absence of a code so unknown codes don't reach MySQL every time
+type cachedUrl struct {
+	Url      models.Url `json:"url"`                 // Cached URL mapping
+	NotFound bool       `json:"not_found,omitempty"` // Whether the code does not exist
+	ExpireAt int64      `json:"expire_at"`           // Unix milliseconds when the cache entry expires
+	Delta    int64      `json:"delta"`               // Milliseconds it took to load the URL from the persistent repository
+}
+
 // RedisMysqlUrlRepository is a URL repository that uses both a persistent backend (MySQL) and a cache (Redis)
+// Concurrent cache misses on the same code are coalesced into a single load per process,
+// and optionally into a single load across instances with a distributed lock
 type RedisMysqlUrlRepository struct {
-	repo  UrlRepository // Underlying persistent repository (e.g., MySQL)
-	redis cache.Cache   // Cache layer (e.g., Redis)
+	repo  UrlRepository      // Underlying persistent repository (e.g., MySQL)
+	redis cache.Cache        // Cache layer (e.g., Redis)
+	group singleflight.Group // Coalesces concurrent loads of the same short code
+
+	locker       cache.Locker   // Distributed lock for cache fills (nil disables it)
+	lockTTL      time.Duration  // Maximum time a fill lock is held
+	lockWait     time.Duration  // Time to wait for another instance's fill before loading anyway
+	lockInterval time.Duration  // Polling interval while waiting for another instance's fill
+	refreshBeta  float64        // XFetch beta; higher refreshes earlier, 0 disables early refresh
+	randomFloat  func() float64 // Source of uniform random numbers in [0, 1) for early refresh
+	negativeTTL  time.Duration  // Time a "not found" result stays cached, 0 disables negative caching
+}
+
+// RedisMysqlUrlRepositoryOption configures optional behaviour of a RedisMysqlUrlRepository
+type RedisMysqlUrlRepositoryOption func(*RedisMysqlUrlRepository)
+
+// WithFillLock makes instances take a distributed lock before repopulating the cache after a miss,
+// so only one instance queries MySQL. Instances that lose the race wait up to wait for the entry to appear.
+func WithFillLock(locker cache.Locker, ttl time.Duration, wait time.Duration) RedisMysqlUrlRepositoryOption {
+	return func(r *RedisMysqlUrlRepository) {
+		r.locker = locker
+		r.lockTTL = ttl
+		r.lockWait = wait
+	}
+}
+
+// WithEarlyRefresh sets the XFetch beta used to refresh cache entries before they expire
+// (1 is the usual value, 0 disables early refresh)
+func WithEarlyRefresh(beta float64) RedisMysqlUrlRepositoryOption {
+	return func(r *RedisMysqlUrlRepository) {
+		r.refreshBeta = beta
+	}
+}
+
+// WithNegativeCache caches "not found" results for ttl (0 disables negative caching)
+func WithNegativeCache(ttl time.Duration) RedisMysqlUrlRepositoryOption {
+	return func(r *RedisMysqlUrlRepository) {
+		r.negativeTTL = ttl
+	}
 }
 
 // NewRedisMysqlUrlRepository creates a new RedisMysqlUrlRepository with the given persistent repo and cache
-func NewRedisMysqlUrlRepository(repo UrlRepository, redis cache.Cache) *RedisMysqlUrlRepository {
-	return &RedisMysqlUrlRepository{
-		repo:  repo,
-		redis: redis,
+func NewRedisMysqlUrlRepository(repo UrlRepository, redis cache.Cache, opts ...RedisMysqlUrlRepositoryOption) *RedisMysqlUrlRepository {
+	r := &RedisMysqlUrlRepository{
+		repo:         repo,
+		redis:        redis,
+		lockInterval: 25 * time.Millisecond,
+		refreshBeta:  1,
+		randomFloat:  rand.Float64,
+		negativeTTL:  30 * time.Second,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Create stores a new URL mapping in the persistent repository
-func (r *RedisMysqlUrlRepository) Create(url models.Url) error {
-	return r.repo.Create(url)
+// and drops any cached "not found" result for its short code
+func (r *RedisMysqlUrlRepository) Create(ctx context.Context, url models.Url) error {
+	if err := r.repo.Create(ctx, url); err != nil {
+		return err
+	}
+	logCacheError("Create", r.redis.Del(ctx, "short:"+url.Key()))
+	return nil
+}
+
+// Update changes a URL mapping in the persistent repository and invalidates its cache entries
+func (r *RedisMysqlUrlRepository) Update(ctx context.Context, url models.Url) error {
+	if err := r.repo.Update(ctx, url); err != nil {
+		return err
+	}
+	err := r.redis.Del(ctx, "short:"+url.Key(), "expire:"+url.Key()) // Drop stale cached URL and expired marker
+	logCacheError("Update", err)
+	return nil
+}
+
+// Delete removes a URL mapping from the persistent repository and invalidates its cache entries
+func (r *RedisMysqlUrlRepository) Delete(ctx context.Context, shortCode string) error {
+	if err := r.repo.Delete(ctx, shortCode); err != nil {
+		return err
+	}
+	err := r.redis.Del(ctx, "short:"+shortCode, "expire:"+shortCode) // Drop cached URL and expired marker
+	logCacheError("Delete", err)
+	return nil
 }
 
 // GetByShortCode retrieves a URL by its short code, using cache and expiration logic
-func (r *RedisMysqlUrlRepository) GetByShortCode(shortCode string) (*models.Url, error) {
-	// Check if the short code is marked as expired in cache
-	expireKey := "expire:" + shortCode
-	_, err := r.redis.Get(expireKey)
-	if err == nil {
-		slog.Error(" [redis_mysql_url_repository.go] [Expired short code] ", slog.Any("error", err))
-		return nil, utils.ErrShortCodeExpired
+// Cache failures are not fatal: lookups fall back to the persistent repository
+func (r *RedisMysqlUrlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	// Try to get the URL from cache first, so hot links need a single cache lookup
+	if entry := r.getCached(ctx, shortCode); entry != nil {
+		if entry.NotFound {
+			return nil, nil // Known not to exist
+		}
+		if isExpired(&entry.Url) {
+			return nil, utils.ErrShortCodeExpired
+		}
+		if !r.shouldRefresh(entry) {
+			return &entry.Url, nil // Return cached URL if found
+		}
+		// Entry is about to expire: reload it now instead of letting every request miss at once
+	} else {
+		// Check if the short code is marked as expired in cache
+		_, err := r.redis.Get(ctx, "expire:"+shortCode)
+		if err == nil {
+			return nil, utils.ErrShortCodeExpired
+		}
+	}
+
+	return r.load(ctx, shortCode)
+}
+
+// getCached returns the cache entry of a short code, or nil if it is missing, unreadable or the cache failed
+func (r *RedisMysqlUrlRepository) getCached(ctx context.Context, shortCode string) *cachedUrl {
+	value, err := r.redis.Get(ctx, "short:"+shortCode)
+	if err != nil {
+		return nil
+	}
+	var entry cachedUrl
+	if err := json.Unmarshal([]byte(value), &entry); err != nil || (entry.Url.ShortURL == "" && !entry.NotFound) {
+		return nil // Unreadable or written in an older format
+	}
+	return &entry
+}
+
+// shouldRefresh decides whether to refresh an entry before it expires (XFetch).
+// The probability grows as expiry approaches and with the time the entry took to load,
+// so a single request usually refreshes a hot entry before concurrent requests miss.
+func (r *RedisMysqlUrlRepository) shouldRefresh(entry *cachedUrl) bool {
+	if r.refreshBeta <= 0 || entry.ExpireAt == 0 {
+		return false
+	}
+	gap := float64(entry.Delta) * r.refreshBeta * -math.Log(1-r.randomFloat())
+	return float64(time.Now().UnixMilli())+gap >= float64(entry.ExpireAt)
+}
+
+// load fetches a short code from the persistent repository and caches it
+// Concurrent loads of the same code in this process share a single fill, which runs
+// detached from the caller's cancellation (bounded by fillTimeout) so one client giving up
+// does not fail everyone waiting on it
+func (r *RedisMysqlUrlRepository) load(ctx context.Context, shortCode string) (*models.Url, error) {
+	result := r.group.DoChan(shortCode, func() (any, error) {
+		fillCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+		defer cancel()
+		return r.fill(fillCtx, shortCode)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*models.Url), nil
 	}
+}
 
-	// Try to get the URL from cache
-	cacheKey := "short:" + shortCode
-	value, err := r.redis.Get(cacheKey)
-	if err == nil {
-		var cacheUrl models.Url
-		if err := json.Unmarshal([]byte(value), &cacheUrl); err == nil {
-			return &cacheUrl, nil // Return cached URL if found
+// fill loads a short code from the persistent repository and repopulates the cache
+// With a fill lock, only the instance holding the lock queries the repository;
+// the others wait briefly for the entry to appear in cache
+func (r *RedisMysqlUrlRepository) fill(ctx context.Context, shortCode string) (*models.Url, error) {
+	if r.locker != nil {
+		lockKey := "lock:short:" + shortCode
+		token, acquired, err := r.locker.Lock(ctx, lockKey, r.lockTTL)
+		switch {
+		case err != nil:
+			// Lock unavailable (e.g., Redis down): load without it
+		case acquired:
+			defer func() { logCacheError("Unlock", r.locker.Unlock(ctx, lockKey, token)) }()
+		default:
+			if entry := r.waitForFill(ctx, shortCode); entry != nil {
+				if entry.NotFound {
+					return nil, nil
+				}
+				if isExpired(&entry.Url) {
+					return nil, utils.ErrShortCodeExpired
+				}
+				return &entry.Url, nil
+			}
+			// The other instance is too slow: load it ourselves
 		}
 	}
 
-	// slog.Info(" [Cache miss] ", slog.String("shortCode", shortCode))
+	start := time.Now()
 
 	// Fallback to persistent repository if not in cache
-	url, err := r.repo.GetByShortCode(shortCode)
-	if err != nil || url == nil {
+	url, err := r.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
 		return nil, err
 	}
-
-	// slog.Info(" [URL found in repo] ", slog.String("shortCode", shortCode))
+	if url == nil {
+		// Remember the miss briefly so scanners probing random codes don't reach MySQL every time
+		if r.negativeTTL > 0 {
+			if entryJson, err := json.Marshal(cachedUrl{NotFound: true}); err == nil {
+				logCacheError("GetByShortCode", r.redis.Set(ctx, "short:"+shortCode, string(entryJson), r.negativeTTL))
+			}
+		}
+		return nil, nil
+	}
 
 	// If the URL is expired, mark it as expired in cache and return error
-	if url.CreatedAt != url.Expire && url.Expire.Before(time.Now()) {
-		expireKey := "expire:" + shortCode
-		r.redis.Set(expireKey, "1", 0) // 0 means never expire in cache
+	if isExpired(url) {
+		logCacheError("GetByShortCode", r.redis.Set(ctx, "expire:"+shortCode, "1", 0)) // 0 means never expire in cache
 		return nil, utils.ErrShortCodeExpired
 	}
 
-	// Cache the URL for future lookups min(5 minutes, actual expiration)
-	urlJson, err := json.Marshal(url)
-	if err == nil {
+	logCacheError("GetByShortCode", r.store(ctx, *url, time.Since(start)))
+	return url, nil
+}
+
+// store caches a URL for min(5 minutes, actual expiration)
+// delta is the time it took to load the URL, used for early refresh
+func (r *RedisMysqlUrlRepository) store(ctx context.Context, url models.Url, delta time.Duration) error {
+	duration := cacheTTL
+	if url.CreatedAt != url.Expire {
+		duration = min(time.Until(url.Expire), duration)
+	}
+	entry := cachedUrl{
+		Url:      url,
+		ExpireAt: time.Now().Add(duration).UnixMilli(),
+		Delta:    delta.Milliseconds(),
+	}
+	entryJson, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(ctx, "short:"+url.Key(), string(entryJson), duration)
+}
+
+// Warm loads up to limit popular URLs from source into the cache, so the first requests
+// after a deploy or a cache restart don't all reach MySQL
+// Stops when ctx is done and returns the number of URLs cached so far
+func (r *RedisMysqlUrlRepository) Warm(ctx context.Context, source PopularUrlLister, limit int) (int, error) {
+	start := time.Now()
+	urls, err := source.ListPopular(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	delta := time.Since(start) / time.Duration(max(len(urls), 1)) // Approximate load time of a single URL
 
-		// slog.Info(" [Created At]", url.CreatedAt.GoString())
-		// slog.Info(" [Expire At]", url.Expire.GoString())
+	warmed := 0
+	for _, url := range urls {
+		if ctx.Err() != nil {
+			return warmed, ctx.Err()
+		}
+		if isExpired(&url) {
+			continue
+		}
+		if err := r.store(ctx, url, delta); err != nil {
+			return warmed, err
+		}
+		warmed++
+	}
+	return warmed, nil
+}
 
-		duration := 5 * time.Minute
-		if url.CreatedAt != url.Expire {
-			expire := url.Expire.Sub(url.CreatedAt)
-			duration = min(expire, duration)
+// waitForFill polls the cache until another instance has filled the entry of a short code
+// Returns nil if the entry does not appear within lockWait
+func (r *RedisMysqlUrlRepository) waitForFill(ctx context.Context, shortCode string) *cachedUrl {
+	deadline := time.Now().Add(r.lockWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.lockInterval):
+		}
+		if entry := r.getCached(ctx, shortCode); entry != nil {
+			return entry
 		}
+	}
+	return nil
+}
 
-		// slog.Info(" [Caching URL] ", slog.String("shortCode", shortCode), slog.String("duration", duration.String()))
+// isExpired reports whether url has an expiration that lies in the past
+func isExpired(url *models.Url) bool {
+	return url.CreatedAt != url.Expire && url.Expire.Before(time.Now())
+}
 
-		r.redis.Set(cacheKey, string(urlJson), duration)
+// logCacheError logs a failed cache write
+// Writes rejected by an open circuit breaker are expected while Redis is down and not logged
+func logCacheError(operation string, err error) {
+	if err == nil || errors.Is(err, utils.ErrCacheUnavailable) {
+		return
 	}
-
-	return url, err
+	slog.Warn(" [redis_mysql_url_repository.go] [CACHE WRITE] ", slog.String("operation", operation), slog.Any("error", err))
 }
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"urlshortener/cache"
+	"urlshortener/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// slowUrlRepository is a UrlRepository that counts lookups and takes a while to answer them
+type slowUrlRepository struct {
+	UrlRepository
+	lookups atomic.Int64
+}
+
+func (s *slowUrlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	s.lookups.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	now := time.Now()
+	return &models.Url{URL: "https://example.com/" + shortCode, ShortURL: shortCode, CreatedAt: now, Expire: now}, nil
+}
+
+// getConcurrently looks up shortCode from n goroutines spread over repos
+func getConcurrently(t *testing.T, repos []*RedisMysqlUrlRepository, shortCode string, n int) {
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url, err := repos[i%len(repos)].GetByShortCode(context.Background(), shortCode)
+			require.NoError(t, err)
+			require.Equal(t, "https://example.com/"+shortCode, url.URL)
+		}()
+	}
+	wg.Wait()
+}
+
+// TestGetByShortCodeCoalescesMisses checks that concurrent misses in one process load the code once
+func TestGetByShortCodeCoalescesMisses(t *testing.T) {
+	backend := &slowUrlRepository{}
+	repo := NewRedisMysqlUrlRepository(backend, cache.NewMemoryCache(1<<20))
+
+	getConcurrently(t, []*RedisMysqlUrlRepository{repo}, "abc123", 50)
+	require.Equal(t, int64(1), backend.lookups.Load())
+
+	// Later lookups are served from cache
+	getConcurrently(t, []*RedisMysqlUrlRepository{repo}, "abc123", 10)
+	require.Equal(t, int64(1), backend.lookups.Load())
+}
+
+// TestGetByShortCodeFillLock checks that with a fill lock only one instance loads a missing code
+func TestGetByShortCodeFillLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	backend := &slowUrlRepository{}
+
+	var repos []*RedisMysqlUrlRepository
+	for range 3 {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		redisCache := cache.NewRedisCache(client, time.Second)
+		repos = append(repos, NewRedisMysqlUrlRepository(backend, redisCache, WithFillLock(redisCache, time.Second, time.Second)))
+	}
+
+	getConcurrently(t, repos, "abc123", 30)
+	require.Equal(t, int64(1), backend.lookups.Load())
+}
+
+// blockedUrlRepository is a UrlRepository whose lookups wait until release is closed, like a stalled database
+type blockedUrlRepository struct {
+	UrlRepository
+	release chan struct{}
+}
+
+func (b *blockedUrlRepository) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	<-b.release
+	return nil, nil
+}
+
+// TestGetByShortCodeCancel checks that cancelling the caller's context aborts a lookup stuck on the backend
+func TestGetByShortCodeCancel(t *testing.T) {
+	backend := &blockedUrlRepository{release: make(chan struct{})}
+	t.Cleanup(func() { close(backend.release) })
+	repo := NewRedisMysqlUrlRepository(backend, cache.NewMemoryCache(1<<20))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := repo.GetByShortCode(ctx, "abc123")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+// TestShouldRefresh checks that early refresh only triggers close to expiry
+func TestShouldRefresh(t *testing.T) {
+	repo := NewRedisMysqlUrlRepository(nil, nil)
+	repo.randomFloat = func() float64 { return 0.5 } // -ln(0.5) ≈ 0.69
+
+	now := time.Now().UnixMilli()
+	require.False(t, repo.shouldRefresh(&cachedUrl{ExpireAt: now + 60_000, Delta: 100}))
+	require.True(t, repo.shouldRefresh(&cachedUrl{ExpireAt: now + 50, Delta: 100}))
+
+	repo.refreshBeta = 0
+	require.False(t, repo.shouldRefresh(&cachedUrl{ExpireAt: now + 50, Delta: 100}))
+}
+
+// popularUrls is a PopularUrlLister returning a fixed list of URLs
+type popularUrls []models.Url
+
+func (p popularUrls) ListPopular(ctx context.Context, limit int) ([]models.Url, error) {
+	return p[:min(limit, len(p))], nil
+}
+
+// TestWarm checks that warmed URLs are served from cache without reaching the persistent repository
+func TestWarm(t *testing.T) {
+	now := time.Now()
+	backend := &slowUrlRepository{}
+	repo := NewRedisMysqlUrlRepository(backend, cache.NewMemoryCache(1<<20))
+
+	warmed, err := repo.Warm(context.Background(), popularUrls{
+		{URL: "https://example.com/abc123", ShortURL: "abc123", CreatedAt: now, Expire: now},
+		{URL: "https://example.com/old", ShortURL: "old", CreatedAt: now.Add(-time.Hour), Expire: now.Add(-time.Minute)},
+	}, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, warmed)
+
+	getConcurrently(t, []*RedisMysqlUrlRepository{repo}, "abc123", 10)
+	require.Equal(t, int64(0), backend.lookups.Load())
+}
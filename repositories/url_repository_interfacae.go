@@ -1,12 +1,34 @@
 package repositories
 
-import "urlshortener/models"
+import (
+	"context"
+	"time"
+	"urlshortener/models"
+)
 
 // UrlRepository defines the interface for URL persistence and retrieval.
 // Implementations may use different storage backends (e.g., MySQL, Redis, etc.).
+// Every method takes a context so client disconnects and shutdown cancel in-flight calls.
 type UrlRepository interface {
 	// Create stores a new URL mapping in the repository.
-	Create(url models.Url) error
+	Create(ctx context.Context, url models.Url) error
 	// GetByShortCode retrieves a URL mapping by its short code.
-	GetByShortCode(shortCode string) (*models.Url, error)
+	GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error)
+	// Update changes the original URL and expiration of an existing short code.
+	Update(ctx context.Context, url models.Url) error
+	// Delete removes a URL mapping by its short code.
+	Delete(ctx context.Context, shortCode string) error
+}
+
+// PopularUrlLister is implemented by repositories that can list the URLs most likely to be requested.
+type PopularUrlLister interface {
+	// ListPopular returns up to limit unexpired URL mappings, most popular first.
+	ListPopular(ctx context.Context, limit int) ([]models.Url, error)
+}
+
+// UrlSearcher is implemented by repositories that can filter and search URL mappings.
+type UrlSearcher interface {
+	// Search returns up to filter.Limit URL mappings matching filter, newest first.
+	// now decides which links count as expired.
+	Search(ctx context.Context, filter models.LinkFilter, now time.Time) ([]models.Url, error)
 }
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"urlshortener/models"
+)
+
+// WorkspaceRepository defines the interface for workspace, member and invitation persistence.
+type WorkspaceRepository interface {
+	// Create stores a new workspace along with its first member and returns the id of the workspace.
+	Create(ctx context.Context, workspace models.Workspace, owner models.Member) (int, error)
+	// GetById retrieves a workspace by its id.
+	GetById(ctx context.Context, id int) (*models.Workspace, error)
+	// ListByAccount returns the workspaces an account is a member of, oldest first.
+	ListByAccount(ctx context.Context, accountId int) ([]models.Membership, error)
+	// GetMember retrieves the membership of an account in a workspace.
+	GetMember(ctx context.Context, workspaceId int, accountId int) (*models.Member, error)
+	// ListMembers returns the members of a workspace, oldest first.
+	ListMembers(ctx context.Context, workspaceId int) ([]models.Member, error)
+	// UpdateRole changes the role of a member, atomically failing with utils.ErrLastOwner if it demotes the only owner.
+	UpdateRole(ctx context.Context, workspaceId int, accountId int, role models.Role) error
+	// RemoveMember removes an account from a workspace, atomically failing with utils.ErrLastOwner if it is the only owner.
+	RemoveMember(ctx context.Context, workspaceId int, accountId int) error
+	// CreateInvitation stores a new invitation and returns its id.
+	CreateInvitation(ctx context.Context, invitation models.Invitation) (int, error)
+	// GetInvitationByTokenHash retrieves an invitation by the SHA-256 hash of its token.
+	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	// AcceptInvitation marks an invitation accepted at the given time and adds member to its workspace, atomically.
+	AcceptInvitation(ctx context.Context, invitationId int, member models.Member, at time.Time) error
+}
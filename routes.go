@@ -0,0 +1,87 @@
+package main
+
+import (
+	"expvar"
+	"urlshortener/handlers"
+	"urlshortener/middleware"
+	"urlshortener/models"
+	"urlshortener/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeHandlers groups the handlers and route-specific middleware mounted by registerRoutes
+type routeHandlers struct {
+	url       *handlers.ShortenHandler  // URL shortening and redirection
+	status    *handlers.StatusHandler   // Service health
+	account   *handlers.AccountHandler  // Accounts and quotas
+	qr        *handlers.QrHandler       // QR codes of short URLs
+	campaign  *handlers.CampaignHandler // Campaigns and their analytics
+	appLinks  *handlers.AppLinksHandler // Association files of the mobile apps
+	domain    *handlers.DomainHandler   // Custom domains of accounts
+	rateLimit *middleware.RateLimiter   // Rate limiting by route and plan
+	quota     gin.HandlerFunc           // Link quota enforcement
+	abuse     gin.HandlerFunc           // Enumeration protection for short code lookups
+
+	workspace  *handlers.WorkspaceHandler     // Workspaces, members and invitations
+	workspaces middleware.WorkspaceAuthorizer // Roles of accounts in workspaces
+	links      *handlers.LinkHandler          // Link listings and search
+}
+
+// registerRoutes registers every HTTP endpoint on the router
+// Every route registered here must be described in openapi/openapi.json
+func registerRoutes(router *gin.Engine, h routeHandlers) {
+	router.GET("/openapi.json", openapi.SpecHandler)                                 // OpenAPI document
+	router.GET("/docs", openapi.SwaggerUIHandler)                                    // Swagger UI
+	router.GET("/status", h.status.GetStatus)                                        // Health of the service and its backends
+	router.GET("/me/quota", h.account.GetQuota)                                      // Plan and quota usage of the client
+	router.GET("/:code", h.abuse, h.url.GetFullURL)                                  // Redirect to original URL ("+" suffix shows the preview page)
+	router.GET("/:code/*path", h.abuse, h.url.GetFullURL)                            // Redirect with trailing path segments
+	router.GET("/fetch/:code", h.abuse, h.url.GetUrlMetadata)                        // Fetch original URL without redirect
+	router.GET("/preview/:code", h.abuse, h.url.GetPreview)                          // Preview page of a short URL
+	router.GET("/qr/:code", h.abuse, h.qr.GetQrCode)                                 // QR code of a short URL
+	router.GET("/stats/:code", h.abuse, h.url.GetStats)                              // Click analytics of a short URL
+	router.GET("/stats/:code/variants", h.abuse, h.url.GetVariantStats)              // Clicks by split variant of a short URL
+	router.POST("/shorten", h.rateLimit.Route("shorten"), h.quota, h.url.ShortenURL) // Create a new short URL
+
+	router.GET("/.well-known/apple-app-site-association", h.appLinks.GetAppleAppSiteAssociation) // iOS universal links
+	router.GET("/.well-known/assetlinks.json", h.appLinks.GetAssetLinks)                         // Android App Links
+
+	campaigns := router.Group("/campaigns", middleware.RequireAccount())
+	campaigns.POST("", h.campaign.CreateCampaign)            // Create a campaign and its UTM template
+	campaigns.GET("/:id", h.campaign.GetCampaign)            // Campaign and its UTM template
+	campaigns.GET("/:id/stats", h.campaign.GetCampaignStats) // Links and clicks of a campaign
+
+	domains := router.Group("/domains", middleware.RequireAccount())
+	domains.POST("", h.domain.AddDomain)               // Add a custom domain
+	domains.GET("", h.domain.ListDomains)              // Custom domains of the account
+	domains.POST("/:id/verify", h.domain.VerifyDomain) // Check the DNS TXT record of a domain
+
+	viewer := middleware.RequireWorkspaceRole(h.workspaces, models.RoleViewer)
+	editor := middleware.RequireWorkspaceRole(h.workspaces, models.RoleEditor)
+	manager := middleware.RequireWorkspaceRole(h.workspaces, models.RoleAdmin)
+	workspaces := router.Group("/workspaces", middleware.RequireAccount())
+	workspaces.POST("", h.workspace.CreateWorkspace)                                               // Create a workspace owned by the account
+	workspaces.GET("", h.workspace.ListWorkspaces)                                                 // Workspaces of the account and its roles
+	workspaces.GET("/:id", viewer, h.workspace.GetWorkspace)                                       // Workspace and the role of the account
+	workspaces.GET("/:id/members", viewer, h.workspace.ListMembers)                                // Members and their roles
+	workspaces.PUT("/:id/members/:account_id", manager, h.workspace.SetMemberRole)                 // Change the role of a member
+	workspaces.DELETE("/:id/members/:account_id", manager, h.workspace.RemoveMember)               // Remove a member
+	workspaces.POST("/:id/invitations", manager, h.workspace.Invite)                               // Invite an account by email
+	workspaces.GET("/:id/links", viewer, h.links.ListWorkspaceLinks)                               // Filter and search the links of the workspace, newest first
+	workspaces.POST("/:id/links", editor, h.rateLimit.Route("shorten"), h.quota, h.url.ShortenURL) // Create a link counted against the workspace's quota
+	workspaces.PATCH("/:id/links/:code", editor, h.url.UpdateLink)                                 // Change the destination, expiration, labels or tags of a link
+	workspaces.DELETE("/:id/links/:code", editor, h.url.DeleteLink)                                // Delete a link
+	workspaces.GET("/:id/stats", viewer, h.workspace.GetStats)                                     // Links and clicks of the workspace
+	workspaces.GET("/:id/quota", viewer, h.workspace.GetQuota)                                     // Quota shared by the workspace's links
+
+	router.POST("/invitations/accept", middleware.RequireAccount(), h.workspace.AcceptInvitation) // Join a workspace with an invitation token
+	router.GET("/links", middleware.RequireAccount(), h.links.SearchLinks)                        // Filter and search the links of the account and its workspaces
+
+	// Metrics expose the command line, memory statistics and abuse counters, so only admins may read them
+	router.GET("/debug/vars", middleware.RequireAdmin(), gin.WrapH(expvar.Handler())) // Runtime metrics (expvar)
+
+	admin := router.Group("/admin", middleware.RequireAdmin())
+	admin.POST("/accounts", h.account.CreateAccount)     // Create an account and its API key
+	admin.PUT("/accounts/:id/quota", h.account.SetQuota) // Change the plan or quota of an account
+}
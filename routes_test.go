@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"urlshortener/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoutesDocumentedInOpenApi fails when a registered Gin route is missing from openapi/openapi.json
+func TestRoutesDocumentedInOpenApi(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	router := gin.New()
+	registerRoutes(router, routeHandlers{})
+
+	for _, route := range router.Routes() {
+		path := openapi.PathFromGin(route.Path)
+		pathItem := spec.Paths.Value(path)
+		require.NotNilf(t, pathItem, "route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+		require.NotNilf(t, pathItem.GetOperation(route.Method), "route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+}
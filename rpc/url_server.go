@@ -0,0 +1,199 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"urlshortener/models"
+	"urlshortener/pb"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UrlServer implements the pb.UrlShortenerServer gRPC service
+// It uses the same UrlService as the HTTP handlers
+type UrlServer struct {
+	pb.UnimplementedUrlShortenerServer
+	UrlService *services.UrlService // Service for URL operations
+}
+
+// NewUrlServer creates a new UrlServer with the given UrlService
+func NewUrlServer(urlService *services.UrlService) *UrlServer {
+	return &UrlServer{
+		UrlService: urlService,
+	}
+}
+
+// Shorten creates a new short URL for the requested URL
+func (s *UrlServer) Shorten(ctx context.Context, req *pb.ShortenRequest) (*pb.ShortenResponse, error) {
+	if err := utils.ValidateUrl(req.GetUrl()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	short, expireAt, err := s.UrlService.CreateShortUrl(ctx, req.GetUrl(), req.GetExpireIn(), userAgent(ctx), services.LinkOptions{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ShortenResponse{
+		ShortCode: short,
+		ShortUrl:  os.Getenv("SHORT_URL_PREFIX") + short,
+		ExpireAt:  expireAt,
+	}, nil
+}
+
+// Resolve returns the original URL of a short code
+// Links with routing rules or country destinations resolve to the destination matching the caller's User-Agent and address,
+// split links to a variant picked at random
+func (s *UrlServer) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	if req.GetShortCode() == "" {
+		return nil, toStatus(utils.ErrShortCodeRequired)
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx, req.GetShortCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ResolveResponse{Url: s.UrlService.Destination(url, s.UrlService.NewVisit(userAgent(ctx), peerIp(ctx), "")).URL}, nil
+}
+
+// GetMetadata returns the stored metadata of a short code
+func (s *UrlServer) GetMetadata(ctx context.Context, req *pb.GetMetadataRequest) (*pb.UrlMetadata, error) {
+	if req.GetShortCode() == "" {
+		return nil, toStatus(utils.ErrShortCodeRequired)
+	}
+
+	url, err := s.UrlService.GetUrlByCode(ctx, req.GetShortCode())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toMetadata(url), nil
+}
+
+// Update changes the original URL and/or expiration of a short code
+// Links of workspaces can only be changed through the HTTP API, by members allowed to
+func (s *UrlServer) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.UrlMetadata, error) {
+	if req.GetShortCode() == "" {
+		return nil, toStatus(utils.ErrShortCodeRequired)
+	}
+	if err := s.checkUnowned(ctx, req.GetShortCode()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	url, err := s.UrlService.UpdateUrl(ctx, req.GetShortCode(), services.LinkUpdate{Url: req.Url, ExpireIn: req.ExpireIn})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toMetadata(url), nil
+}
+
+// Delete removes a short code
+// Links of workspaces can only be deleted through the HTTP API, by members allowed to
+func (s *UrlServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	if req.GetShortCode() == "" {
+		return nil, toStatus(utils.ErrShortCodeRequired)
+	}
+	if err := s.checkUnowned(ctx, req.GetShortCode()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.UrlService.DeleteUrl(ctx, req.GetShortCode()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteResponse{}, nil
+}
+
+// checkUnowned returns utils.ErrForbidden if a short code belongs to a workspace, whose permissions gRPC clients can't prove
+func (s *UrlServer) checkUnowned(ctx context.Context, shortCode string) error {
+	url, err := s.UrlService.GetUrlByCode(ctx, shortCode)
+	if err != nil {
+		return err
+	}
+	if url.WorkspaceId != nil {
+		return utils.ErrForbidden
+	}
+	return nil
+}
+
+// userAgent returns the User-Agent sent by the gRPC client, if any
+func userAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("user-agent"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerIp returns the IP address of the caller, or an empty string if unknown
+func peerIp(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// toMetadata converts a Url model to its protobuf representation
+// ExpireAt is left unset if the URL never expires
+func toMetadata(url *models.Url) *pb.UrlMetadata {
+	meta := &pb.UrlMetadata{
+		Url:       url.URL,
+		ShortCode: url.ShortURL,
+		CreatedAt: timestamppb.New(url.CreatedAt),
+	}
+	if !url.Expire.Equal(url.CreatedAt) {
+		meta.ExpireAt = timestamppb.New(url.Expire)
+	}
+	return meta
+}
+
+// grpcCodes maps the stable error codes of utils.AppError to gRPC status codes
+var grpcCodes = map[string]codes.Code{
+	"link_not_found":       codes.NotFound,
+	"link_expired":         codes.FailedPrecondition,
+	"invalid_url":          codes.InvalidArgument,
+	"url_too_short":        codes.InvalidArgument,
+	"short_code_required":  codes.InvalidArgument,
+	"validation_error":     codes.InvalidArgument,
+	"link_already_exists":  codes.AlreadyExists,
+	"short_code_collision": codes.AlreadyExists,
+	"rate_limit_exceeded":  codes.ResourceExhausted,
+	"service_unavailable":  codes.Unavailable,
+	"forbidden":            codes.PermissionDenied,
+}
+
+// toStatus maps errors from utils to gRPC status errors
+// The machine-readable code of the utils.AppError is attached as an ErrorInfo reason
+// Unknown errors are reported as Internal without leaking details
+func toStatus(err error) error {
+	appErr := utils.ToAppError(err)
+
+	code, ok := grpcCodes[appErr.Code]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, appErr.Message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: appErr.Code, Domain: "urlshortener"}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
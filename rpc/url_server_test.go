@@ -0,0 +1,173 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+	"urlshortener/models"
+	"urlshortener/pb"
+	"urlshortener/repositories"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// memoryUrlRepo stores links in memory, keyed by models.LinkKey
+type memoryUrlRepo struct {
+	repositories.UrlRepository
+	mu    sync.Mutex
+	links map[string]models.Url
+}
+
+func (m *memoryUrlRepo) Create(ctx context.Context, url models.Url) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links[url.Key()] = url
+	return nil
+}
+
+func (m *memoryUrlRepo) GetByShortCode(ctx context.Context, shortCode string) (*models.Url, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if url, ok := m.links[shortCode]; ok {
+		return &url, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryUrlRepo) Update(ctx context.Context, url models.Url) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.links[url.Key()]; !ok {
+		return utils.ErrUrlNotFound
+	}
+	m.links[url.Key()] = url
+	return nil
+}
+
+func (m *memoryUrlRepo) Delete(ctx context.Context, shortCode string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.links[shortCode]; !ok {
+		return utils.ErrUrlNotFound
+	}
+	delete(m.links, shortCode)
+	return nil
+}
+
+// newTestClient serves a UrlServer backed by repo over an in-memory connection and returns a client of it
+func newTestClient(t *testing.T, repo *memoryUrlRepo) pb.UrlShortenerClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterUrlShortenerServer(server, NewUrlServer(services.NewUrlService(repo, nil)))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewUrlShortenerClient(conn)
+}
+
+// TestUrlServer checks the gRPC methods end to end against an in-memory repository
+func TestUrlServer(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("SHORT_URL_PREFIX", "https://sho.rt/")
+	repo := &memoryUrlRepo{links: map[string]models.Url{}}
+	client := newTestClient(t, repo)
+
+	_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "not a url"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/some/long/landing-page", ExpireIn: 60})
+	require.NoError(t, err)
+	require.Equal(t, "https://sho.rt/"+created.GetShortCode(), created.GetShortUrl())
+	require.NotEqual(t, "no expiration", created.GetExpireAt())
+
+	resolved, err := client.Resolve(ctx, &pb.ResolveRequest{ShortCode: created.GetShortCode()})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/some/long/landing-page", resolved.GetUrl())
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{ShortCode: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	destination, noExpiry := "https://example.com/another/long/landing-page", int64(0)
+	updated, err := client.Update(ctx, &pb.UpdateRequest{ShortCode: created.GetShortCode(), Url: &destination, ExpireIn: &noExpiry})
+	require.NoError(t, err)
+	require.Equal(t, destination, updated.GetUrl())
+	require.Nil(t, updated.GetExpireAt())
+	_, err = client.Update(ctx, &pb.UpdateRequest{ShortCode: "missing", Url: &destination})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Delete(ctx, &pb.DeleteRequest{ShortCode: created.GetShortCode()})
+	require.NoError(t, err)
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{ShortCode: created.GetShortCode()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Delete(ctx, &pb.DeleteRequest{ShortCode: created.GetShortCode()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// TestUrlServerWorkspaceLinks checks that gRPC clients can resolve workspace links but not change them
+func TestUrlServerWorkspaceLinks(t *testing.T) {
+	ctx := context.Background()
+	workspaceId, now := 1, time.Now()
+	repo := &memoryUrlRepo{links: map[string]models.Url{
+		"team": {URL: "https://example.com/team/landing-page", ShortURL: "team", CreatedAt: now, Expire: now, WorkspaceId: &workspaceId},
+	}}
+	client := newTestClient(t, repo)
+
+	resolved, err := client.Resolve(ctx, &pb.ResolveRequest{ShortCode: "team"})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/team/landing-page", resolved.GetUrl())
+
+	destination := "https://attacker.example/phishing/landing-page"
+	_, err = client.Update(ctx, &pb.UpdateRequest{ShortCode: "team", Url: &destination})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.Delete(ctx, &pb.DeleteRequest{ShortCode: "team"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Equal(t, "https://example.com/team/landing-page", repo.links["team"].URL) // Unchanged
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"missing link", utils.ErrUrlNotFound, codes.NotFound, "link_not_found"},
+		{"wrapped missing link", fmt.Errorf("update: %w", utils.ErrUrlNotFound), codes.NotFound, "link_not_found"},
+		{"expired link", utils.ErrShortCodeExpired, codes.FailedPrecondition, "link_expired"},
+		{"invalid url", utils.ErrInvalidUrl, codes.InvalidArgument, "invalid_url"},
+		{"missing short code", utils.ErrShortCodeRequired, codes.InvalidArgument, "short_code_required"},
+		{"workspace link", utils.ErrForbidden, codes.PermissionDenied, "forbidden"},
+		{"unknown error", errors.New("dial tcp 10.0.0.5:3306: connection refused"), codes.Internal, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatus(tt.err))
+			require.True(t, ok)
+			require.Equal(t, tt.code, st.Code())
+			require.NotContains(t, st.Message(), "10.0.0.5") // Internal details are not leaked
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			require.Equal(t, tt.reason, info.Reason)
+		})
+	}
+}
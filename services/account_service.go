@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/repositories"
+	"urlshortener/utils"
+)
+
+// apiKeyPrefix makes API keys easy to recognise (e.g., by secret scanners)
+const apiKeyPrefix = "usk_"
+
+// AccountService provides methods to manage accounts and authenticate their API keys
+type AccountService struct {
+	AccountRepo repositories.AccountRepository // Underlying repository for account data
+	plans       quota.Plans                    // Plans accounts may be assigned to
+}
+
+// NewAccountService creates a new AccountService with the given repository and plans
+func NewAccountService(repo repositories.AccountRepository, plans quota.Plans) *AccountService {
+	return &AccountService{
+		AccountRepo: repo,
+		plans:       plans,
+	}
+}
+
+// hashApiKey returns the SHA-256 hash of an API key, the only form in which keys are stored
+func hashApiKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkPlan returns utils.ErrUnknownPlan unless accounts may be assigned to plan
+func (a *AccountService) checkPlan(plan string) error {
+	if _, ok := a.plans[plan]; !ok || plan == quota.PlanAnonymous {
+		return utils.ErrUnknownPlan
+	}
+	return nil
+}
+
+// Authenticate returns the account owning apiKey and the id of the key
+// Returns utils.ErrUnauthorized if the key is unknown
+func (a *AccountService) Authenticate(ctx context.Context, apiKey string) (*models.Account, int, error) {
+	account, keyId, err := a.AccountRepo.GetByApiKeyHash(ctx, hashApiKey(apiKey))
+	if err != nil {
+		return nil, 0, err
+	}
+	if account == nil {
+		return nil, 0, utils.ErrUnauthorized
+	}
+	return account, keyId, nil
+}
+
+// CreateAccount creates an account on plan along with its first API key
+// Returns the account and the API key, which cannot be retrieved later
+func (a *AccountService) CreateAccount(ctx context.Context, email string, plan string, admin bool) (*models.Account, string, error) {
+	if err := a.checkPlan(plan); err != nil {
+		return nil, "", err
+	}
+
+	account := models.Account{
+		Email:     email,
+		Plan:      plan,
+		Admin:     admin,
+		CreatedAt: time.Now(),
+	}
+	id, err := a.AccountRepo.Create(ctx, account)
+	if err != nil {
+		return nil, "", err
+	}
+	account.Id = id
+
+	secret := make([]byte, 24)
+	rand.Read(secret)
+	apiKey := apiKeyPrefix + hex.EncodeToString(secret)
+	if _, err := a.AccountRepo.CreateApiKey(ctx, id, hashApiKey(apiKey)); err != nil {
+		slog.Error(" [account_service.go] [CreateAccount] ", slog.Int("account", id), slog.Any("error", err))
+		return nil, "", err
+	}
+	return &account, apiKey, nil
+}
+
+// SetQuota changes the plan of an account and overrides its daily and monthly link quotas
+// A nil quota uses the plan's, 0 makes it unlimited
+// Returns utils.ErrAccountNotFound if the account does not exist
+func (a *AccountService) SetQuota(ctx context.Context, id int, plan string, daily *int, monthly *int) (*models.Account, error) {
+	if err := a.checkPlan(plan); err != nil {
+		return nil, err
+	}
+
+	account, err := a.AccountRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+
+	account.Plan, account.DailyQuota, account.MonthlyQuota = plan, daily, monthly
+	if err := a.AccountRepo.UpdateQuota(ctx, *account); err != nil {
+		slog.Error(" [account_service.go] [SetQuota] ", slog.Int("account", id), slog.Any("error", err))
+		return nil, err
+	}
+	return account, nil
+}
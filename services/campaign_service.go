@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"urlshortener/models"
+	"urlshortener/repositories"
+	"urlshortener/utils"
+)
+
+// CampaignService provides methods to manage campaigns and report on their links
+type CampaignService struct {
+	CampaignRepo repositories.CampaignRepository // Underlying repository for campaign data
+	ClickRepo    repositories.ClickRepository    // Clicks of the campaigns' links
+}
+
+// NewCampaignService creates a new CampaignService with the given repositories
+func NewCampaignService(campaigns repositories.CampaignRepository, clicks repositories.ClickRepository) *CampaignService {
+	return &CampaignService{
+		CampaignRepo: campaigns,
+		ClickRepo:    clicks,
+	}
+}
+
+// CreateCampaign creates a campaign of account with a template of UTM parameters
+// Returns utils.ErrCampaignAlreadyExists if the account already has a campaign called name
+func (c *CampaignService) CreateCampaign(ctx context.Context, account *models.Account, name string, utm models.UTM) (*models.Campaign, error) {
+	campaign := models.Campaign{
+		AccountId: account.Id,
+		Name:      name,
+		UTM:       utm,
+		CreatedAt: time.Now(),
+	}
+	id, err := c.CampaignRepo.Create(ctx, campaign)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Id = id
+	return &campaign, nil
+}
+
+// GetCampaign retrieves a campaign that account may use: its own, or any campaign for admins
+// Returns utils.ErrUnauthorized for anonymous clients and utils.ErrCampaignNotFound otherwise,
+// so the campaigns of other accounts can't be discovered
+func (c *CampaignService) GetCampaign(ctx context.Context, account *models.Account, id int) (*models.Campaign, error) {
+	if account == nil {
+		return nil, utils.ErrUnauthorized
+	}
+	campaign, err := c.CampaignRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil || (campaign.AccountId != account.Id && !account.Admin) {
+		return nil, utils.ErrCampaignNotFound
+	}
+	return campaign, nil
+}
+
+// GetStats summarizes the clicks of every link of a campaign that account may use
+func (c *CampaignService) GetStats(ctx context.Context, account *models.Account, id int) (*models.CampaignStats, error) {
+	campaign, err := c.GetCampaign(ctx, account, id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := c.ClickRepo.CampaignStats(ctx, campaign.Id)
+	if err != nil {
+		slog.Error(" [campaign_service.go] [GetStats] ", slog.Int("campaign", id), slog.Any("error", err))
+		return nil, err
+	}
+	stats.Name = campaign.Name
+	return stats, nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+	"urlshortener/models"
+	"urlshortener/repositories"
+)
+
+// clickBatchSize is the largest number of clicks inserted by a single statement
+const clickBatchSize = 500
+
+// clickFlushTimeout bounds the time spent storing a batch of clicks
+const clickFlushTimeout = 5 * time.Second
+
+// ClickCounters holds the counters of a ClickService
+type ClickCounters struct {
+	Recorded int64 `json:"recorded"` // Clicks stored
+	Dropped  int64 `json:"dropped"`  // Clicks discarded because the buffer was full
+	Failed   int64 `json:"failed"`   // Clicks lost because storing them failed
+}
+
+// ClickService records clicks in the background, so redirects never wait for the database
+// Clicks are buffered and stored in batches; when the buffer is full new clicks are dropped
+type ClickService struct {
+	repo     repositories.ClickRepository // Storage of clicks
+	queue    chan models.Click            // Buffered clicks waiting to be stored
+	stop     chan struct{}                // Closed by Close to flush and stop Run
+	done     chan struct{}                // Closed when Run returns
+	stopOnce sync.Once                    // Guards close(stop)
+	recorded atomic.Int64                 // Clicks stored
+	dropped  atomic.Int64                 // Clicks discarded because the buffer was full
+	failed   atomic.Int64                 // Clicks lost because storing them failed
+}
+
+// NewClickService creates a ClickService buffering up to bufferSize clicks
+func NewClickService(repo repositories.ClickRepository, bufferSize int) *ClickService {
+	return &ClickService{
+		repo:  repo,
+		queue: make(chan models.Click, bufferSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Record queues a click of a short code that sent visit to target, without blocking
+// A nil *ClickService records nothing
+func (c *ClickService) Record(shortCode string, target Target, visit Visit) {
+	if c == nil {
+		return
+	}
+	click := models.Click{
+		ShortURL:    shortCode,
+		ClickedAt:   time.Now(),
+		Destination: target.URL,
+		Country:     visit.Country,
+		OS:          visit.Agent.OS,
+		Device:      visit.Agent.Device,
+		Browser:     visit.Agent.Browser,
+		Referrer:    visit.Referrer,
+		Variant:     target.Variant,
+	}
+	select {
+	case c.queue <- click:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// Run stores queued clicks whenever a batch is full or flushEvery has elapsed, until Close is called
+func (c *ClickService) Run(flushEvery time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(flushEvery)
+	defer ticker.Stop()
+
+	batch := make([]models.Click, 0, clickBatchSize)
+	for {
+		select {
+		case click := <-c.queue:
+			batch = append(batch, click)
+			if len(batch) == clickBatchSize {
+				batch = c.flush(batch)
+			}
+		case <-ticker.C:
+			batch = c.flush(batch)
+		case <-c.stop:
+			// Store whatever is still buffered before returning
+			for {
+				select {
+				case click := <-c.queue:
+					batch = append(batch, click)
+					if len(batch) == clickBatchSize {
+						batch = c.flush(batch)
+					}
+				default:
+					c.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush stores a batch of clicks and returns the emptied batch
+func (c *ClickService) flush(batch []models.Click) []models.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clickFlushTimeout)
+	defer cancel()
+	if err := c.repo.CreateBatch(ctx, batch); err != nil {
+		c.failed.Add(int64(len(batch)))
+		slog.Error(" [click_service.go] [FLUSH] ", slog.Int("clicks", len(batch)), slog.Any("error", err))
+	} else {
+		c.recorded.Add(int64(len(batch)))
+	}
+	return batch[:0]
+}
+
+// Close stores the buffered clicks and stops Run, waiting until it returns or ctx is done
+func (c *ClickService) Close(ctx context.Context) {
+	c.stopOnce.Do(func() { close(c.stop) })
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+}
+
+// Stats returns the counters of the service
+func (c *ClickService) Stats() ClickCounters {
+	return ClickCounters{
+		Recorded: c.recorded.Load(),
+		Dropped:  c.dropped.Load(),
+		Failed:   c.failed.Load(),
+	}
+}
+
+// GetStats summarizes the clicks of a short code
+func (c *ClickService) GetStats(ctx context.Context, shortCode string) (*models.ClickStats, error) {
+	stats, err := c.repo.Stats(ctx, shortCode)
+	if err != nil {
+		slog.Error(" [click_service.go] [GetStats] ", slog.Any("error", err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetVariantStats counts the clicks of each current variant of a split link
+// Links without a split have no variants
+func (c *ClickService) GetVariantStats(ctx context.Context, url *models.Url) (*models.VariantStats, error) {
+	stats := &models.VariantStats{ShortURL: url.ShortURL, Variants: []models.VariantClicks{}}
+	if url.Split == nil {
+		return stats, nil
+	}
+
+	counts, err := c.repo.VariantClicks(ctx, url.Key())
+	if err != nil {
+		slog.Error(" [click_service.go] [GetVariantStats] ", slog.Any("error", err))
+		return nil, err
+	}
+	for _, variant := range url.Split.Variants {
+		clicks := counts[variant.Name]
+		stats.Total += clicks
+		stats.Variants = append(stats.Variants, models.VariantClicks{
+			Name:   variant.Name,
+			URL:    variant.URL,
+			Weight: variant.Weight,
+			Clicks: clicks,
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"urlshortener/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryClickRepo stores clicks in memory
+type memoryClickRepo struct {
+	mu      sync.Mutex
+	batches [][]models.Click
+}
+
+func (m *memoryClickRepo) CreateBatch(ctx context.Context, clicks []models.Click) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]models.Click(nil), clicks...))
+	return nil
+}
+
+func (m *memoryClickRepo) VariantClicks(ctx context.Context, shortCode string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]int)
+	for _, batch := range m.batches {
+		for _, click := range batch {
+			if click.ShortURL == shortCode && click.Variant != "" {
+				counts[click.Variant]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (m *memoryClickRepo) CampaignStats(ctx context.Context, campaignId int) (*models.CampaignStats, error) {
+	return &models.CampaignStats{CampaignId: campaignId}, nil
+}
+
+func (m *memoryClickRepo) WorkspaceStats(ctx context.Context, workspaceId int) (*models.WorkspaceStats, error) {
+	return &models.WorkspaceStats{WorkspaceId: workspaceId}, nil
+}
+
+func (m *memoryClickRepo) Stats(ctx context.Context, shortCode string) (*models.ClickStats, error) {
+	return &models.ClickStats{ShortURL: shortCode}, nil
+}
+
+func TestClickServiceFlushesOnClose(t *testing.T) {
+	repo := &memoryClickRepo{}
+	clicks := NewClickService(repo, 3)
+	go clicks.Run(time.Hour)
+
+	visit := Visit{Country: "FR", Referrer: "news.example.com"}
+	visit.Agent.OS, visit.Agent.Device, visit.Agent.Browser = "ios", "mobile", "safari"
+	for range 5 {
+		clicks.Record("abc123", Target{URL: "https://example.com/fr"}, visit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	clicks.Close(ctx)
+
+	var stored []models.Click
+	for _, batch := range repo.batches {
+		stored = append(stored, batch...)
+	}
+	stats := clicks.Stats()
+	require.Equal(t, int64(len(stored)), stats.Recorded)
+	require.Equal(t, int64(5), stats.Recorded+stats.Dropped)
+	require.NotEmpty(t, stored)
+	require.Equal(t, "FR", stored[0].Country)
+	require.Equal(t, "mobile", stored[0].Device)
+	require.Equal(t, "news.example.com", stored[0].Referrer)
+
+	var disabled *ClickService
+	disabled.Record("abc123", Target{URL: "https://example.com"}, visit) // Must not panic
+}
+
+func TestClickServiceVariantStats(t *testing.T) {
+	repo := &memoryClickRepo{}
+	clicks := NewClickService(repo, 10)
+	go clicks.Run(time.Hour)
+
+	url := &models.Url{ShortURL: "abc123", Split: &models.Split{Variants: []models.Variant{
+		{Name: "a", URL: "https://example.com/landing-a", Weight: 70},
+		{Name: "b", URL: "https://example.com/landing-b", Weight: 30},
+	}}}
+	for _, variant := range []string{"a", "b", "a", ""} {
+		clicks.Record("abc123", Target{URL: "https://example.com/landing", Variant: variant}, Visit{})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	clicks.Close(ctx)
+
+	stats, err := clicks.GetVariantStats(ctx, url)
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Total)
+	require.Equal(t, []models.VariantClicks{
+		{Name: "a", URL: "https://example.com/landing-a", Weight: 70, Clicks: 2},
+		{Name: "b", URL: "https://example.com/landing-b", Weight: 30, Clicks: 1},
+	}, stats.Variants)
+}
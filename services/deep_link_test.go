@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"urlshortener/models"
+	"urlshortener/useragent"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDeepLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		deepLink *models.DeepLink
+		wantErr  error
+	}{
+		{"none", nil, nil},
+		{"custom scheme", &models.DeepLink{IOS: "myapp://product/42"}, nil},
+		{"intent", &models.DeepLink{Android: "intent://product/42#Intent;scheme=myapp;package=com.example.app;end"}, nil},
+		{"empty", &models.DeepLink{}, utils.ErrInvalidDeepLink},
+		{"no scheme", &models.DeepLink{IOS: "product/42"}, utils.ErrInvalidDeepLink},
+		{"javascript", &models.DeepLink{IOS: "myapp://ok", Android: "JavaScript:alert(1)"}, utils.ErrInvalidDeepLink},
+		{"data", &models.DeepLink{IOS: "data:text/html,hi"}, utils.ErrInvalidDeepLink},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantErr, ValidateDeepLink(tt.deepLink))
+		})
+	}
+}
+
+func TestDeepLinkAppURI(t *testing.T) {
+	deepLink := &models.DeepLink{IOS: "myapp://ios", Android: "myapp://android"}
+
+	require.Equal(t, "myapp://ios", deepLink.AppURI(useragent.Parse("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1")))
+	require.Equal(t, "myapp://android", deepLink.AppURI(useragent.Parse("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36")))
+	require.Empty(t, deepLink.AppURI(useragent.Parse("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")))
+	require.Empty(t, deepLink.AppURI(useragent.Parse("Mozilla/5.0 (Linux; Android 14) Googlebot/2.1")))
+	require.Empty(t, (*models.DeepLink)(nil).AppURI(useragent.Parse("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")))
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+	"urlshortener/models"
+	"urlshortener/repositories"
+	"urlshortener/utils"
+)
+
+// TXTResolver looks up DNS TXT records
+// *net.Resolver implements it; tests use a fake
+type TXTResolver interface {
+	// LookupTXT returns the TXT records of name
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainService manages the custom domains of accounts and resolves request hosts to them
+type DomainService struct {
+	DomainRepo repositories.DomainRepository   // Underlying repository for domain data
+	resolver   TXTResolver                     // DNS lookups of verification records
+	verified   atomic.Pointer[map[string]bool] // Hosts of verified domains, replaced on refresh
+}
+
+// NewDomainService creates a new DomainService with the given repository and DNS resolver
+// No custom domain is served until Refresh loads the verified ones
+func NewDomainService(repo repositories.DomainRepository, resolver TXTResolver) *DomainService {
+	service := &DomainService{
+		DomainRepo: repo,
+		resolver:   resolver,
+	}
+	service.verified.Store(&map[string]bool{})
+	return service
+}
+
+// hostName matches lower-case host names with at least two labels
+var hostName = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeHost lower-cases a host name and strips its port and trailing dot
+func NormalizeHost(host string) string {
+	host = strings.ToLower(host)
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// AddDomain adds a custom domain to account along with a random verification token
+// Other accounts may claim the host too until one of them verifies it, so a pending claim can't reserve a host
+// Returns utils.ErrInvalidDomain if host isn't a valid host name
+// and utils.ErrDomainAlreadyExists if the account already added it or it is verified by any account
+func (d *DomainService) AddDomain(ctx context.Context, account *models.Account, host string) (*models.Domain, error) {
+	host = NormalizeHost(host)
+	if len(host) > 253 || !hostName.MatchString(host) {
+		return nil, utils.ErrInvalidDomain
+	}
+	claims, err := d.DomainRepo.ListByHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims) > 0 && claims[0].Verified() {
+		return nil, utils.ErrDomainAlreadyExists
+	}
+
+	token := make([]byte, 16)
+	rand.Read(token)
+	domain := models.Domain{
+		AccountId:         account.Id,
+		Host:              host,
+		VerificationToken: hex.EncodeToString(token),
+		CreatedAt:         time.Now(),
+	}
+	id, err := d.DomainRepo.Create(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	domain.Id = id
+	return &domain, nil
+}
+
+// ListDomains returns the domains of account
+func (d *DomainService) ListDomains(ctx context.Context, account *models.Account) ([]models.Domain, error) {
+	return d.DomainRepo.ListByAccount(ctx, account.Id)
+}
+
+// GetDomain retrieves a domain that account may manage: its own, or any domain for admins
+// Returns utils.ErrDomainNotFound otherwise, so the domains of other accounts can't be discovered
+func (d *DomainService) GetDomain(ctx context.Context, account *models.Account, id int) (*models.Domain, error) {
+	domain, err := d.DomainRepo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil || account == nil || (domain.AccountId != account.Id && !account.Admin) {
+		return nil, utils.ErrDomainNotFound
+	}
+	return domain, nil
+}
+
+// VerifyDomain checks that the verification TXT record of a domain is published and starts serving the domain
+// The claims of the host by other accounts are dropped
+// Returns utils.ErrDomainNotVerified if the record is missing or holds another token
+// and utils.ErrDomainAlreadyExists if another account verified the host first
+func (d *DomainService) VerifyDomain(ctx context.Context, account *models.Account, id int) (*models.Domain, error) {
+	domain, err := d.GetDomain(ctx, account, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.Verified() {
+		return domain, nil
+	}
+
+	name, value := domain.VerificationRecord()
+	records, err := d.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		slog.Info(" [domain_service.go] [VerifyDomain] ", slog.String("host", domain.Host), slog.Any("error", err))
+		return nil, utils.ErrDomainNotVerified
+	}
+	found := false
+	for _, record := range records {
+		found = found || strings.TrimSpace(record) == value
+	}
+	if !found {
+		return nil, utils.ErrDomainNotVerified
+	}
+
+	now := time.Now()
+	if err := d.DomainRepo.MarkVerified(ctx, domain.Id, now); err != nil {
+		return nil, err
+	}
+	domain.VerifiedAt = &now
+	d.serve(domain.Host)
+	return domain, nil
+}
+
+// serve adds host to the verified hosts
+func (d *DomainService) serve(host string) {
+	for {
+		current := d.verified.Load()
+		next := make(map[string]bool, len(*current)+1)
+		for verified := range *current {
+			next[verified] = true
+		}
+		next[host] = true
+		if d.verified.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
+
+// LinkDomain returns the host of the domain account wants a new link on ("" for the default domain)
+// Returns utils.ErrDomainNotFound if account doesn't own the domain and utils.ErrDomainNotVerified if it isn't verified yet
+func (d *DomainService) LinkDomain(ctx context.Context, account *models.Account, host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+	claims, err := d.DomainRepo.ListByHost(ctx, NormalizeHost(host))
+	if err != nil {
+		return "", err
+	}
+	for _, domain := range claims {
+		if account == nil || (domain.AccountId != account.Id && !account.Admin) {
+			continue
+		}
+		if !domain.Verified() {
+			return "", utils.ErrDomainNotVerified
+		}
+		return domain.Host, nil
+	}
+	return "", utils.ErrDomainNotFound
+}
+
+// Match returns the verified domain a request Host header refers to, or "" for the default domain
+func (d *DomainService) Match(host string) string {
+	host = NormalizeHost(host)
+	if (*d.verified.Load())[host] {
+		return host
+	}
+	return ""
+}
+
+// Refresh reloads the hosts of verified domains, including those verified on other instances
+func (d *DomainService) Refresh(ctx context.Context) error {
+	hosts, err := d.DomainRepo.ListVerifiedHosts(ctx)
+	if err != nil {
+		slog.Error(" [domain_service.go] [Refresh] ", slog.Any("error", err))
+		return err
+	}
+	verified := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		verified[host] = true
+	}
+	d.verified.Store(&verified)
+	return nil
+}
+
+// RefreshEvery reloads the verified hosts every interval until ctx is cancelled
+func (d *DomainService) RefreshEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Refresh(ctx)
+		}
+	}
+}
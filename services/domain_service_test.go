@@ -0,0 +1,231 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDomainRepo stores domains in memory
+type memoryDomainRepo struct {
+	mu      sync.Mutex
+	domains []models.Domain
+	nextId  int
+}
+
+func (m *memoryDomainRepo) Create(ctx context.Context, domain models.Domain) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.domains {
+		if existing.Host == domain.Host && existing.AccountId == domain.AccountId {
+			return 0, utils.ErrDomainAlreadyExists
+		}
+	}
+	m.nextId++
+	domain.Id = m.nextId
+	m.domains = append(m.domains, domain)
+	return domain.Id, nil
+}
+
+func (m *memoryDomainRepo) find(match func(models.Domain) bool) *models.Domain {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, domain := range m.domains {
+		if match(domain) {
+			return &domain
+		}
+	}
+	return nil
+}
+
+func (m *memoryDomainRepo) GetById(ctx context.Context, id int) (*models.Domain, error) {
+	return m.find(func(d models.Domain) bool { return d.Id == id }), nil
+}
+
+func (m *memoryDomainRepo) ListByHost(ctx context.Context, host string) ([]models.Domain, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var domains []models.Domain
+	for _, domain := range m.domains {
+		if domain.Host == host {
+			domains = append(domains, domain)
+		}
+	}
+	slices.SortStableFunc(domains, func(a, b models.Domain) int {
+		pending := func(d models.Domain) int {
+			if d.Verified() {
+				return 0
+			}
+			return 1
+		}
+		return cmp.Compare(pending(a), pending(b)) // Verified first
+	})
+	return domains, nil
+}
+
+func (m *memoryDomainRepo) ListByAccount(ctx context.Context, accountId int) ([]models.Domain, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var domains []models.Domain
+	for _, domain := range m.domains {
+		if domain.AccountId == accountId {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+func (m *memoryDomainRepo) ListVerifiedHosts(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var hosts []string
+	for _, domain := range m.domains {
+		if domain.Verified() {
+			hosts = append(hosts, domain.Host)
+		}
+	}
+	return hosts, nil
+}
+
+func (m *memoryDomainRepo) MarkVerified(ctx context.Context, id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := slices.IndexFunc(m.domains, func(d models.Domain) bool { return d.Id == id })
+	if i < 0 {
+		return utils.ErrDomainNotFound
+	}
+	host := m.domains[i].Host
+	if slices.ContainsFunc(m.domains, func(d models.Domain) bool { return d.Host == host && d.Verified() }) {
+		return utils.ErrDomainAlreadyExists
+	}
+	m.domains[i].VerifiedAt = &at
+	m.domains = slices.DeleteFunc(m.domains, func(d models.Domain) bool { return d.Host == host && !d.Verified() })
+	return nil
+}
+
+// fakeResolver answers TXT lookups from a map
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if records, ok := f[name]; ok {
+		return records, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestNormalizeHost(t *testing.T) {
+	require.Equal(t, "go.example.com", NormalizeHost("Go.Example.COM:8443"))
+	require.Equal(t, "go.example.com", NormalizeHost("go.example.com."))
+	require.Equal(t, "[::1]", NormalizeHost("[::1]:3000"))
+	require.Equal(t, "[::1]", NormalizeHost("[::1]"))
+}
+
+func TestAddDomainRejectsInvalidHosts(t *testing.T) {
+	ctx := context.Background()
+	domains := NewDomainService(&memoryDomainRepo{}, fakeResolver{})
+	account := &models.Account{Id: 1}
+
+	for _, host := range []string{"localhost", "-bad.example.com", "go_links.example.com", "192.168.0.1", "go..example.com"} {
+		_, err := domains.AddDomain(ctx, account, host)
+		require.ErrorIs(t, err, utils.ErrInvalidDomain, host)
+	}
+
+	domain, err := domains.AddDomain(ctx, account, "Go.Example.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", domain.Host)
+	require.Len(t, domain.VerificationToken, 32)
+
+	_, err = domains.AddDomain(ctx, account, "GO.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainAlreadyExists)
+}
+
+// TestCompetingDomainClaims checks that a pending claim doesn't reserve a host
+// and that the first account to verify it keeps it
+func TestCompetingDomainClaims(t *testing.T) {
+	ctx := context.Background()
+	resolver := fakeResolver{}
+	domains := NewDomainService(&memoryDomainRepo{}, resolver)
+	squatter, owner, late := &models.Account{Id: 1}, &models.Account{Id: 2}, &models.Account{Id: 3}
+
+	squatted, err := domains.AddDomain(ctx, squatter, "go.example.com")
+	require.NoError(t, err)
+	claimed, err := domains.AddDomain(ctx, owner, "go.example.com")
+	require.NoError(t, err)
+	_, err = domains.LinkDomain(ctx, owner, "go.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainNotVerified)
+
+	name, value := claimed.VerificationRecord()
+	resolver[name] = []string{value}
+	_, err = domains.VerifyDomain(ctx, squatter, squatted.Id) // The record holds the other claim's token
+	require.ErrorIs(t, err, utils.ErrDomainNotVerified)
+	_, err = domains.VerifyDomain(ctx, owner, claimed.Id)
+	require.NoError(t, err)
+
+	// Other claims are dropped and the host can't be claimed again
+	_, err = domains.GetDomain(ctx, squatter, squatted.Id)
+	require.ErrorIs(t, err, utils.ErrDomainNotFound)
+	_, err = domains.LinkDomain(ctx, squatter, "go.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainNotFound)
+	_, err = domains.AddDomain(ctx, late, "go.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainAlreadyExists)
+
+	host, err := domains.LinkDomain(ctx, owner, "go.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", host)
+	host, err = domains.LinkDomain(ctx, &models.Account{Id: 4, Admin: true}, "go.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", host)
+}
+
+func TestVerifyDomain(t *testing.T) {
+	ctx := context.Background()
+	resolver := fakeResolver{}
+	repo := &memoryDomainRepo{}
+	domains := NewDomainService(repo, resolver)
+	owner, other := &models.Account{Id: 1}, &models.Account{Id: 2}
+
+	domain, err := domains.AddDomain(ctx, owner, "go.example.com")
+	require.NoError(t, err)
+	_, err = domains.LinkDomain(ctx, owner, "go.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainNotVerified)
+
+	// Missing or wrong record
+	_, err = domains.VerifyDomain(ctx, owner, domain.Id)
+	require.ErrorIs(t, err, utils.ErrDomainNotVerified)
+	name, value := domain.VerificationRecord()
+	resolver[name] = []string{"shortener-verification=someone-else"}
+	_, err = domains.VerifyDomain(ctx, owner, domain.Id)
+	require.ErrorIs(t, err, utils.ErrDomainNotVerified)
+	require.Empty(t, domains.Match("go.example.com"))
+
+	// Other accounts can't see the domain
+	_, err = domains.VerifyDomain(ctx, other, domain.Id)
+	require.ErrorIs(t, err, utils.ErrDomainNotFound)
+
+	resolver[name] = []string{"v=spf1 -all", value}
+	verified, err := domains.VerifyDomain(ctx, owner, domain.Id)
+	require.NoError(t, err)
+	require.True(t, verified.Verified())
+	require.Equal(t, "go.example.com", domains.Match("GO.example.com:443"))
+	require.Empty(t, domains.Match("example.com"))
+
+	host, err := domains.LinkDomain(ctx, owner, "go.example.com")
+	require.NoError(t, err)
+	require.Equal(t, "go.example.com", host)
+	_, err = domains.LinkDomain(ctx, other, "go.example.com")
+	require.ErrorIs(t, err, utils.ErrDomainNotFound)
+
+	// Other instances pick the domain up on refresh
+	refreshed := NewDomainService(repo, resolver)
+	require.Empty(t, refreshed.Match("go.example.com"))
+	require.NoError(t, refreshed.Refresh(ctx))
+	require.Equal(t, "go.example.com", refreshed.Match("go.example.com"))
+}
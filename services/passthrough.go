@@ -0,0 +1,63 @@
+package services
+
+import (
+	neturl "net/url"
+	"strings"
+	"urlshortener/models"
+)
+
+// Passthrough carries the incoming query string and trailing path segments of a link over to its destination,
+// for links created with ForwardQuery or ForwardPath
+// extraPath is the part of the request path after the short code (e.g., /docs/intro), rawQuery the encoded query string
+//
+// Query parameters of the destination win over incoming parameters with the same name, so visitors can't override
+// the parameters the link was created with; other incoming parameters are appended in their original order.
+// Path segments are appended to the destination path after dropping empty, "." and ".." segments,
+// so a visitor can't climb above the destination path. Both are re-encoded.
+// Returns destination unchanged if it can't be parsed.
+func Passthrough(url *models.Url, destination string, extraPath string, rawQuery string) string {
+	if !url.ForwardQuery && !url.ForwardPath {
+		return destination
+	}
+	target, err := neturl.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	if url.ForwardPath {
+		var segments []string
+		for _, segment := range strings.Split(extraPath, "/") {
+			if segment != "" && segment != "." && segment != ".." {
+				segments = append(segments, segment)
+			}
+		}
+		if len(segments) > 0 {
+			target = target.JoinPath(segments...)
+		}
+	}
+
+	if url.ForwardQuery && rawQuery != "" {
+		existing := target.Query()
+		var extra []string
+		for _, pair := range strings.Split(rawQuery, "&") {
+			if pair == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(pair, "=")
+			key, keyErr := neturl.QueryUnescape(key)
+			value, valueErr := neturl.QueryUnescape(value)
+			if keyErr != nil || valueErr != nil || key == "" || existing.Has(key) {
+				continue // Malformed, or set by the destination
+			}
+			extra = append(extra, neturl.QueryEscape(key)+"="+neturl.QueryEscape(value))
+		}
+		if len(extra) > 0 {
+			if target.RawQuery != "" {
+				extra = append([]string{target.RawQuery}, extra...)
+			}
+			target.RawQuery = strings.Join(extra, "&")
+		}
+	}
+
+	return target.String()
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"urlshortener/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassthrough(t *testing.T) {
+	both := &models.Url{ForwardQuery: true, ForwardPath: true}
+	tests := []struct {
+		name        string
+		url         *models.Url
+		destination string
+		extraPath   string
+		rawQuery    string
+		want        string
+	}{
+		{"disabled", &models.Url{}, "https://example.com/docs", "/intro", "ref=email", "https://example.com/docs"},
+		{"query appended", both, "https://example.com/docs", "", "ref=email&lang=en", "https://example.com/docs?ref=email&lang=en"},
+		{"destination wins", both, "https://example.com/docs?ref=print&b=1", "", "ref=email&a=2", "https://example.com/docs?ref=print&b=1&a=2"},
+		{"repeated keys kept", both, "https://example.com/docs", "", "tag=a&tag=b", "https://example.com/docs?tag=a&tag=b"},
+		{"re-encoded", both, "https://example.com/docs", "", "q=a+b&name=%C3%A9t%C3%A9&x=%26", "https://example.com/docs?q=a+b&name=%C3%A9t%C3%A9&x=%26"},
+		{"malformed skipped", both, "https://example.com/docs", "", "bad=%zz&=empty&ok=1", "https://example.com/docs?ok=1"},
+		{"path appended", both, "https://example.com/docs/", "/guide/intro", "", "https://example.com/docs/guide/intro"},
+		{"dot segments dropped", both, "https://example.com/docs", "/../../admin/./x", "", "https://example.com/docs/admin/x"},
+		{"path escaped", both, "https://example.com/docs", "/a b/c?d", "", "https://example.com/docs/a%20b/c%3Fd"},
+		{"path and query", both, "https://example.com/docs?v=2#top", "/intro", "ref=email", "https://example.com/docs/intro?v=2&ref=email#top"},
+		{"query only", &models.Url{ForwardQuery: true}, "https://example.com/docs", "/intro", "ref=email", "https://example.com/docs?ref=email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Passthrough(tt.url, tt.destination, tt.extraPath, tt.rawQuery))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/utils"
+)
+
+// QuotaService enforces the daily and monthly link quotas of workspaces, accounts and anonymous clients
+type QuotaService struct {
+	counter quota.Counter // Links created per client and period
+	plans   quota.Plans   // Limits of every plan
+}
+
+// NewQuotaService creates a new QuotaService counting links with counter
+func NewQuotaService(counter quota.Counter, plans quota.Plans) *QuotaService {
+	return &QuotaService{
+		counter: counter,
+		plans:   plans,
+	}
+}
+
+// limits returns the key counting the links of a client, its plan and its limits
+// Links created in a workspace are counted against the workspace, whatever member created them
+// Anonymous clients (nil account) are counted by clientKey (e.g., their IP address)
+func (q *QuotaService) limits(account *models.Account, workspace *models.Workspace, clientKey string) (string, string, quota.Limits) {
+	switch {
+	case workspace != nil:
+		return "workspace:" + strconv.Itoa(workspace.Id), workspace.Plan, q.planLimits(workspace.Plan, workspace.DailyQuota, workspace.MonthlyQuota, slog.Int("workspace", workspace.Id))
+	case account != nil:
+		return "account:" + strconv.Itoa(account.Id), account.Plan, q.planLimits(account.Plan, account.DailyQuota, account.MonthlyQuota, slog.Int("account", account.Id))
+	default:
+		return "anonymous:" + clientKey, quota.PlanAnonymous, q.plans[quota.PlanAnonymous]
+	}
+}
+
+// planLimits returns the limits of a plan with the daily and monthly overrides applied
+// Unknown plans fall back to the free plan
+func (q *QuotaService) planLimits(plan string, daily *int, monthly *int, owner slog.Attr) quota.Limits {
+	limits, ok := q.plans[plan]
+	if !ok {
+		slog.Warn(" [quota_service.go] [UNKNOWN PLAN] ", owner, slog.String("plan", plan))
+		limits = q.plans[quota.PlanFree]
+	}
+	if daily != nil {
+		limits.Daily = *daily // Admin override
+	}
+	if monthly != nil {
+		limits.Monthly = *monthly
+	}
+	return limits
+}
+
+// Consume counts a link created at now by a client, in workspace if not nil
+// Links created in a workspace also count against the account creating them, so creating workspaces
+// can't give an account more links than its own plan; the usage closest to its limit is returned
+// Returns utils.ErrQuotaExceeded along with the usage if the client has no link left
+func (q *QuotaService) Consume(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string, now time.Time) (quota.Usage, error) {
+	if workspace == nil || account == nil {
+		return q.consume(ctx, account, workspace, clientKey, now)
+	}
+
+	accountUsage, err := q.consume(ctx, account, nil, clientKey, now)
+	if err != nil {
+		return accountUsage, err
+	}
+	workspaceUsage, err := q.consume(ctx, account, workspace, clientKey, now)
+	if err != nil {
+		q.Refund(context.WithoutCancel(ctx), account, nil, clientKey, now) // The link isn't created
+		return workspaceUsage, err
+	}
+	if remaining(accountUsage) < remaining(workspaceUsage) {
+		return accountUsage, nil
+	}
+	return workspaceUsage, nil
+}
+
+// consume counts a link created at now against a single quota
+func (q *QuotaService) consume(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string, now time.Time) (quota.Usage, error) {
+	key, plan, limits := q.limits(account, workspace, clientKey)
+	used, ok, err := q.counter.Consume(ctx, key, limits, now)
+	if err != nil {
+		return quota.Usage{}, err
+	}
+	usage := quota.NewUsage(plan, limits, used, now)
+	if !ok {
+		return usage, utils.ErrQuotaExceeded
+	}
+	return usage, nil
+}
+
+// remaining returns the number of links usage allows before either period runs out
+func remaining(usage quota.Usage) int {
+	left := math.MaxInt
+	for _, period := range []quota.Period{usage.Daily, usage.Monthly} {
+		if period.Remaining != nil {
+			left = min(left, *period.Remaining)
+		}
+	}
+	return left
+}
+
+// Refund takes back a link counted at now, e.g. because creating it failed
+// Links of a workspace are given back to the account that created them as well
+func (q *QuotaService) Refund(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string, now time.Time) error {
+	key, _, _ := q.limits(account, workspace, clientKey)
+	err := q.counter.Refund(ctx, key, now)
+	if workspace != nil && account != nil {
+		if accountErr := q.Refund(ctx, account, nil, clientKey, now); err == nil {
+			err = accountErr
+		}
+	}
+	return err
+}
+
+// Usage returns the quota of a client, or of workspace if not nil
+func (q *QuotaService) Usage(ctx context.Context, account *models.Account, workspace *models.Workspace, clientKey string) (quota.Usage, error) {
+	now := time.Now()
+	key, plan, limits := q.limits(account, workspace, clientKey)
+	used, err := q.counter.Get(ctx, key, now)
+	if err != nil {
+		return quota.Usage{}, err
+	}
+	return quota.NewUsage(plan, limits, used, now), nil
+}
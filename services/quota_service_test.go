@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"urlshortener/models"
+	"urlshortener/quota"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConsumeWorkspaceQuota checks that workspace links count against the account creating them too,
+// and that the account gets its link back when the workspace has none left
+func TestConsumeWorkspaceQuota(t *testing.T) {
+	ctx, now := context.Background(), time.Now()
+	quotas := NewQuotaService(quota.NewMemoryCounter(), quota.Plans{quota.PlanFree: {Daily: 3, Monthly: 10}})
+	account := &models.Account{Id: 1, Plan: quota.PlanFree}
+	daily := 1
+	workspace := &models.Workspace{Id: 1, Plan: quota.PlanFree, DailyQuota: &daily}
+
+	usage, err := quotas.Consume(ctx, account, workspace, "", now)
+	require.NoError(t, err)
+	require.Equal(t, 0, *usage.Daily.Remaining) // The workspace is closer to its limit
+
+	_, err = quotas.Consume(ctx, account, workspace, "", now)
+	require.ErrorIs(t, err, utils.ErrQuotaExceeded)
+	accountUsage, err := quotas.Usage(ctx, account, nil, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, accountUsage.Daily.Used) // Refunded
+
+	// Another workspace of the account draws on the same account quota
+	other := &models.Workspace{Id: 2, Plan: quota.PlanFree}
+	usage, err = quotas.Consume(ctx, account, other, "", now)
+	require.NoError(t, err)
+	require.Equal(t, 1, *usage.Daily.Remaining) // The account is closer to its limit
+	_, err = quotas.Consume(ctx, account, nil, "", now)
+	require.NoError(t, err)
+	_, err = quotas.Consume(ctx, account, other, "", now)
+	require.ErrorIs(t, err, utils.ErrQuotaExceeded)
+
+	require.NoError(t, quotas.Refund(ctx, account, other, "", now))
+	accountUsage, err = quotas.Usage(ctx, account, nil, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, accountUsage.Daily.Used)
+	otherUsage, err := quotas.Usage(ctx, account, other, "")
+	require.NoError(t, err)
+	require.Zero(t, otherUsage.Daily.Used)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"urlshortener/models"
+	"urlshortener/repositories"
+	"urlshortener/utils"
+)
+
+// SearchService provides methods to filter and search links
+type SearchService struct {
+	UrlSearcher repositories.UrlSearcher // Underlying repository searching URL data
+}
+
+// NewSearchService creates a new SearchService with the given repository
+func NewSearchService(urls repositories.UrlSearcher) *SearchService {
+	return &SearchService{
+		UrlSearcher: urls,
+	}
+}
+
+// Search returns up to filter.Limit links matching filter, newest first
+// The id of the last link is the cursor of the next page (filter.Before), 0 once there are no more links
+// Returns utils.ErrInvalidTag if a tag of the filter is invalid, or utils.ErrValidation for an unknown expiry state
+func (s *SearchService) Search(ctx context.Context, filter models.LinkFilter) ([]models.Url, int, error) {
+	switch filter.Expiry {
+	case "", models.ExpiryActive, models.ExpiryExpired, models.ExpiryNever:
+	default:
+		return nil, 0, utils.ErrValidation
+	}
+	tags, err := NormalizeTags(filter.Tags)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.Tags = tags
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	limit := filter.Limit
+	filter.Limit = limit + 1 // One more link tells whether there is a next page
+	urls, err := s.UrlSearcher.Search(ctx, filter, time.Now())
+	if err != nil {
+		slog.Error(" [search_service.go] [Search] ", slog.Any("error", err))
+		return nil, 0, err
+	}
+	if len(urls) <= limit {
+		return urls, 0, nil
+	}
+	urls = urls[:limit]
+	return urls, urls[limit-1].Id, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUrlSearcher filters a fixed set of links by workspace and tags, newest (highest id) first
+type memoryUrlSearcher []models.Url
+
+func (m memoryUrlSearcher) Search(ctx context.Context, filter models.LinkFilter, now time.Time) ([]models.Url, error) {
+	var urls []models.Url
+	for i := len(m) - 1; i >= 0 && len(urls) < filter.Limit; i-- {
+		url := m[i]
+		if filter.WorkspaceId != nil && (url.WorkspaceId == nil || *url.WorkspaceId != *filter.WorkspaceId) {
+			continue
+		}
+		if filter.Before != 0 && url.Id >= filter.Before {
+			continue
+		}
+		if slices.ContainsFunc(filter.Tags, func(tag string) bool { return !slices.Contains(url.Tags, tag) }) {
+			continue
+		}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+		err  error
+	}{
+		{"none", nil, nil, nil},
+		{"trimmed and lower-cased", []string{" Launch ", "Q3.2025"}, []string{"launch", "q3.2025"}, nil},
+		{"sorted without duplicates", []string{"b", "a", "B"}, []string{"a", "b"}, nil},
+		{"unicode letters", []string{"Café crème"}, []string{"café crème"}, nil},
+		{"empty", []string{"  "}, nil, utils.ErrInvalidTag},
+		{"comma", []string{"a,b"}, nil, utils.ErrInvalidTag},
+		{"too long", []string{strings.Repeat("x", 33)}, nil, utils.ErrInvalidTag},
+		{"too many", strings.Split("a b c d e f g h i j k l m n o p q r s t u", " "), nil, utils.ErrInvalidTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := NormalizeTags(tt.tags)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tags)
+		})
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	ctx := context.Background()
+	workspaceId, otherId := 1, 2
+	var urls memoryUrlSearcher
+	for id := 1; id <= 6; id++ {
+		owner := &workspaceId
+		if id == 3 {
+			owner = &otherId
+		}
+		var tags []string
+		if id != 6 {
+			tags = []string{"launch"}
+		}
+		urls = append(urls, models.Url{Id: id, WorkspaceId: owner, Tags: tags})
+	}
+	search := NewSearchService(urls)
+
+	var ids []int
+	filter := models.LinkFilter{WorkspaceId: &workspaceId, Tags: []string{" Launch"}, Limit: 2}
+	for page := 0; ; page++ {
+		require.Less(t, page, 3)
+		links, next, err := search.Search(ctx, filter)
+		require.NoError(t, err)
+		for _, link := range links {
+			ids = append(ids, link.Id)
+		}
+		if next == 0 {
+			break
+		}
+		filter.Before = next
+	}
+	require.Equal(t, []int{5, 4, 2, 1}, ids)
+
+	_, _, err := search.Search(ctx, models.LinkFilter{Expiry: "soon", Limit: 2})
+	require.ErrorIs(t, err, utils.ErrValidation)
+	_, _, err = search.Search(ctx, models.LinkFilter{Tags: []string{"a,b"}, Limit: 2})
+	require.ErrorIs(t, err, utils.ErrInvalidTag)
+}
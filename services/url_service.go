@@ -1,10 +1,18 @@
 package services
 
 import (
+	"context"
 	"log/slog"
+	"math/rand/v2"
+	neturl "net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 	"urlshortener/models"
 	"urlshortener/repositories"
+	"urlshortener/useragent"
 	"urlshortener/utils"
 )
 
@@ -12,20 +20,293 @@ import (
 // It uses a UrlRepository for persistence and lookup
 type UrlService struct {
 	UrlRepo repositories.UrlRepository // Underlying repository for URL data
+	agents  *useragent.Parser          // Memoized User-Agent parser used by routing rules
+	geo     GeoLocator                 // Country lookup of visitor IP addresses (nil disables it)
+	roll    func(n int) int            // Random number in [0, n) used to pick split variants
 }
 
+// GeoLocator resolves the country of an IP address
+type GeoLocator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of ip, or an empty string if unknown
+	Country(ip string) string
+}
+
+// userAgentMemoSize is the number of parsed User-Agent strings kept by UrlService
+const userAgentMemoSize = 10000
+
 // NewUrlService creates a new UrlService with the given repository
-func NewUrlService(repo repositories.UrlRepository) *UrlService {
+// geo may be nil, in which case visitors have no country
+func NewUrlService(repo repositories.UrlRepository, geo GeoLocator) *UrlService {
 	return &UrlService{
 		UrlRepo: repo,
+		agents:  useragent.NewParser(userAgentMemoSize),
+		geo:     geo,
+		roll:    rand.IntN,
 	}
 }
 
+// LinkOptions holds the optional settings of a new short URL
+type LinkOptions struct {
+	Preview      bool                 // Always show the preview page instead of redirecting
+	Rules        []models.RoutingRule // Alternate destinations chosen by the visitor's User-Agent
+	Countries    map[string]string    // Alternate destinations by ISO country code of the visitor
+	Split        *models.Split        // Weighted destinations for A/B tests
+	ForwardQuery bool                 // Merge the query string of the request into the destination
+	ForwardPath  bool                 // Append path segments following the short code to the destination
+	UTM          *models.UTM          // UTM parameters added to every destination, replacing existing ones
+	Campaign     *models.Campaign     // Campaign the link belongs to, whose UTM template fills in missing parameters
+	DeepLink     *models.DeepLink     // App URIs tried on mobile before the web destination
+	Domain       string               // Verified custom domain of the link ("" for the default domain)
+	WorkspaceId  *int                 // Workspace owning the link (nil for none)
+	CreatedBy    *int                 // Account creating the link (nil for anonymous clients)
+
+	Title  string   // Title shown in link listings
+	Notes  string   // Free-form notes
+	Folder string   // Folder the link is filed under
+	Tags   []string // Tags, normalized with NormalizeTags
+}
+
+// LinkUpdate holds the fields of a link to change; nil fields are left unchanged
+type LinkUpdate struct {
+	Url      *string   // New destination
+	ExpireIn *int64    // New expiration in minutes from now (0 removes the expiration)
+	Title    *string   // New title ("" removes it)
+	Notes    *string   // New notes ("" removes them)
+	Folder   *string   // New folder ("" moves the link out of folders)
+	Tags     *[]string // Tags replacing the current ones (empty removes them all)
+}
+
+// applyUTM adds the UTM parameters of opts and of its campaign to the default destination and every alternate destination of url
+func applyUTM(url *models.Url, opts LinkOptions) {
+	var explicit, defaults models.UTM
+	if opts.UTM != nil {
+		explicit = *opts.UTM
+	}
+	if opts.Campaign != nil {
+		defaults = opts.Campaign.UTM
+	}
+	if len(explicit.Params()) == 0 && len(defaults.Params()) == 0 {
+		return
+	}
+
+	url.URL = ApplyUTM(url.URL, explicit, defaults)
+	for i := range url.Rules {
+		url.Rules[i].URL = ApplyUTM(url.Rules[i].URL, explicit, defaults)
+	}
+	for country, target := range url.Countries {
+		url.Countries[country] = ApplyUTM(target, explicit, defaults)
+	}
+	if url.Split != nil {
+		for i := range url.Split.Variants {
+			url.Split.Variants[i].URL = ApplyUTM(url.Split.Variants[i].URL, explicit, defaults)
+		}
+	}
+}
+
+// ValidateRules checks that every rule has a valid URL and matches on at least one field
+// Returns utils.ErrInvalidRoutingRule if a rule is rejected
+func ValidateRules(rules []models.RoutingRule) error {
+	for _, rule := range rules {
+		if rule.OS == "" && rule.Device == "" && rule.Browser == "" {
+			return utils.ErrInvalidRoutingRule
+		}
+		if utils.ValidateUrl(rule.URL) == utils.ErrInvalidUrl {
+			return utils.ErrInvalidRoutingRule
+		}
+	}
+	return nil
+}
+
+// NormalizeCountries upper-cases the country codes of a country→URL map and checks every entry
+// Returns utils.ErrInvalidCountryTarget if a code is not two letters or a URL is invalid
+func NormalizeCountries(countries map[string]string) (map[string]string, error) {
+	if len(countries) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(countries))
+	for code, target := range countries {
+		code = strings.ToUpper(code)
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, utils.ErrInvalidCountryTarget
+		}
+		if utils.ValidateUrl(target) == utils.ErrInvalidUrl {
+			return nil, utils.ErrInvalidCountryTarget
+		}
+		normalized[code] = target
+	}
+	return normalized, nil
+}
+
+// maxTags is the largest number of tags of a link
+const maxTags = 20
+
+// tagName matches the allowed tags once normalized; tags are stored comma-separated, so commas are excluded
+var tagName = regexp.MustCompile(`^[\p{L}\p{N}_. -]{1,32}$`)
+
+// NormalizeTags trims and lower-cases tags, then sorts them and drops duplicates
+// Returns utils.ErrInvalidTag if there are more than maxTags tags or one doesn't match tagName
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagName.MatchString(tag) {
+			return nil, utils.ErrInvalidTag
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > maxTags {
+		return nil, utils.ErrInvalidTag
+	}
+	return normalized, nil
+}
+
+// maxVariants is the largest number of variants of a split
+const maxVariants = 10
+
+// variantName matches the allowed names of split variants, which are also stored in cookies
+var variantName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ValidateSplit checks that a split has 2 to maxVariants uniquely named variants with positive weights and valid URLs
+// Returns utils.ErrInvalidSplit if the split is rejected
+func ValidateSplit(split *models.Split) error {
+	if split == nil {
+		return nil
+	}
+	if len(split.Variants) < 2 || len(split.Variants) > maxVariants {
+		return utils.ErrInvalidSplit
+	}
+	names := make(map[string]bool, len(split.Variants))
+	for _, variant := range split.Variants {
+		if !variantName.MatchString(variant.Name) || names[variant.Name] {
+			return utils.ErrInvalidSplit
+		}
+		if variant.Weight < 1 || variant.Weight > 10000 {
+			return utils.ErrInvalidSplit
+		}
+		if utils.ValidateUrl(variant.URL) == utils.ErrInvalidUrl {
+			return utils.ErrInvalidSplit
+		}
+		names[variant.Name] = true
+	}
+	return nil
+}
+
+// unsafeSchemes are the URI schemes rejected in deep links, since the bounce page navigates to them from script
+var unsafeSchemes = map[string]bool{"javascript": true, "data": true, "vbscript": true, "file": true}
+
+// ValidateDeepLink checks that a deep link has at least one app URI and that every app URI has a safe scheme
+// Returns utils.ErrInvalidDeepLink if the deep link is rejected
+func ValidateDeepLink(deepLink *models.DeepLink) error {
+	if deepLink == nil {
+		return nil
+	}
+	if deepLink.IOS == "" && deepLink.Android == "" {
+		return utils.ErrInvalidDeepLink
+	}
+	for _, uri := range []string{deepLink.IOS, deepLink.Android} {
+		if uri == "" {
+			continue
+		}
+		parsed, err := neturl.Parse(uri)
+		if err != nil || parsed.Scheme == "" || unsafeSchemes[strings.ToLower(parsed.Scheme)] || len(uri) > 2048 {
+			return utils.ErrInvalidDeepLink
+		}
+	}
+	return nil
+}
+
+// Visit describes a visitor following a short URL
+type Visit struct {
+	Agent    useragent.Agent // Parsed User-Agent
+	Country  string          // ISO country code of the visitor (empty if unknown)
+	Referrer string          // Host of the referring page (empty for direct visits)
+	Variant  string          // Split variant assigned on an earlier visit (empty if none)
+}
+
+// Target is the destination chosen for a visit
+type Target struct {
+	URL     string // Where the visitor is redirected
+	Variant string // Split variant the visitor was assigned (empty if the URL doesn't come from a split)
+}
+
+// maxReferrerLength is the width of clicks.referrer; longer hosts would make the click insert fail
+const maxReferrerLength = 255
+
+// NewVisit classifies a visitor by User-Agent, IP address and Referer header
+func (u *UrlService) NewVisit(userAgent string, ip string, referer string) Visit {
+	visit := Visit{Agent: u.agents.Parse(userAgent)}
+	if u.geo != nil {
+		visit.Country = u.geo.Country(ip)
+	}
+	if parsed, err := neturl.Parse(referer); err == nil {
+		visit.Referrer = truncateUTF8(parsed.Hostname(), maxReferrerLength)
+	}
+	return visit
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// Destination returns where a visitor is redirected:
+// the first matching routing rule, else the visitor's country, else a variant of the split, else the original URL
+// Sticky splits keep the variant of the visit if it still exists, other splits pick a variant at random by weight
+func (u *UrlService) Destination(url *models.Url, visit Visit) Target {
+	if !url.Targeted() {
+		return Target{URL: url.URL}
+	}
+	if target, ok := url.Target(visit.Agent, visit.Country); ok {
+		return Target{URL: target}
+	}
+	if url.Split == nil || len(url.Split.Variants) == 0 {
+		return Target{URL: url.URL}
+	}
+
+	if url.Split.Sticky {
+		if variant := url.Split.Variant(visit.Variant); variant != nil {
+			return Target{URL: variant.URL, Variant: variant.Name}
+		}
+	}
+	variant := url.Split.Pick(u.roll(url.Split.TotalWeight()))
+	return Target{URL: variant.URL, Variant: variant.Name}
+}
+
 // CreateShortUrl generates a short URL for the given original URL
 // expireIn is the expiration time in minutes (0 means no expiration)
 // userAgent is used to help generate a unique short code
+// opts holds the optional settings of the link; its UTM parameters are merged into the destinations before storing
 // Returns the short code or an error if creation fails
-func (u *UrlService) CreateShortUrl(url string, expireIn int64, userAgent string) (string, string, error) {
+func (u *UrlService) CreateShortUrl(ctx context.Context, url string, expireIn int64, userAgent string, opts LinkOptions) (string, string, error) {
+	if err := ValidateRules(opts.Rules); err != nil {
+		return "", "", err
+	}
+	countries, err := NormalizeCountries(opts.Countries)
+	if err != nil {
+		return "", "", err
+	}
+	if err := ValidateSplit(opts.Split); err != nil {
+		return "", "", err
+	}
+	if err := ValidateDeepLink(opts.DeepLink); err != nil {
+		return "", "", err
+	}
+	tags, err := NormalizeTags(opts.Tags)
+	if err != nil {
+		return "", "", err
+	}
+
 	uniqueId := utils.UniqueId(userAgent)      // Generate a unique ID based on user agent
 	short := utils.GetShortUrl(url + uniqueId) // Generate a short code using the URL and unique ID
 
@@ -38,7 +319,7 @@ func (u *UrlService) CreateShortUrl(url string, expireIn int64, userAgent string
 	}
 
 	// Check if the short code already exists (collision check)
-	existShort, err := u.UrlRepo.GetByShortCode(short)
+	existShort, err := u.UrlRepo.GetByShortCode(ctx, models.LinkKey(opts.Domain, short))
 	if err != nil {
 		slog.Error(" [url_service.go] [CreateShortUrl] ", slog.Any("error", err))
 		return "", "", err
@@ -49,13 +330,31 @@ func (u *UrlService) CreateShortUrl(url string, expireIn int64, userAgent string
 
 	// Create the Url model
 	shortUrl := models.Url{
-		URL:       url,
-		ShortURL:  short,
-		CreatedAt: createdAt,
-		Expire:    expireAt,
+		URL:          url,
+		ShortURL:     short,
+		Domain:       opts.Domain,
+		CreatedAt:    createdAt,
+		Expire:       expireAt,
+		Preview:      opts.Preview,
+		Rules:        opts.Rules,
+		Countries:    countries,
+		Split:        opts.Split,
+		ForwardQuery: opts.ForwardQuery,
+		ForwardPath:  opts.ForwardPath,
+		DeepLink:     opts.DeepLink,
+		WorkspaceId:  opts.WorkspaceId,
+		CreatedBy:    opts.CreatedBy,
+		Title:        opts.Title,
+		Notes:        opts.Notes,
+		Folder:       opts.Folder,
+		Tags:         tags,
+	}
+	if opts.Campaign != nil {
+		shortUrl.CampaignId = &opts.Campaign.Id
 	}
+	applyUTM(&shortUrl, opts)
 
-	err = u.UrlRepo.Create(shortUrl)
+	err = u.UrlRepo.Create(ctx, shortUrl)
 	if err != nil {
 		slog.Error(" [url_service.go] [CREATE] ", slog.Any("error", err))
 	}
@@ -68,10 +367,10 @@ func (u *UrlService) CreateShortUrl(url string, expireIn int64, userAgent string
 	return short, expireMsg, err
 }
 
-// GetUrlByCode retrieves the original URL by its short code
+// GetUrlByCode retrieves the original URL by its short code, or by its models.LinkKey on a custom domain
 // Returns the Url model or an error if not found
-func (u *UrlService) GetUrlByCode(code string) (*models.Url, error) {
-	url, err := u.UrlRepo.GetByShortCode(code)
+func (u *UrlService) GetUrlByCode(ctx context.Context, code string) (*models.Url, error) {
+	url, err := u.UrlRepo.GetByShortCode(ctx, code)
 	if err != nil {
 		slog.Error(" [url_service.go] [GetUrlByCode] ", slog.Any("error", err))
 		return nil, err
@@ -81,3 +380,58 @@ func (u *UrlService) GetUrlByCode(code string) (*models.Url, error) {
 	}
 	return url, nil
 }
+
+// UpdateUrl changes the destination, expiration, labels and/or tags of an existing short code
+// Fields of update that are nil leave the corresponding field unchanged
+// Returns the updated Url model or an error if the code is missing, expired or the update fails
+func (u *UrlService) UpdateUrl(ctx context.Context, code string, update LinkUpdate) (*models.Url, error) {
+	existing, err := u.GetUrlByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *existing
+	if update.Url != nil {
+		if err := utils.ValidateUrl(*update.Url); err != nil {
+			return nil, err
+		}
+		updated.URL = *update.Url
+	}
+	if update.ExpireIn != nil {
+		if *update.ExpireIn > 0 {
+			updated.Expire = time.Now().Add(time.Duration(*update.ExpireIn) * time.Minute) // Set new expiration
+		} else {
+			updated.Expire = updated.CreatedAt // No expiration, set to creation time
+		}
+	}
+	if update.Title != nil {
+		updated.Title = *update.Title
+	}
+	if update.Notes != nil {
+		updated.Notes = *update.Notes
+	}
+	if update.Folder != nil {
+		updated.Folder = *update.Folder
+	}
+	if update.Tags != nil {
+		if updated.Tags, err = NormalizeTags(*update.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.UrlRepo.Update(ctx, updated); err != nil {
+		slog.Error(" [url_service.go] [UPDATE] ", slog.Any("error", err))
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteUrl removes a short code
+// Returns utils.ErrUrlNotFound if the code does not exist
+func (u *UrlService) DeleteUrl(ctx context.Context, code string) error {
+	err := u.UrlRepo.Delete(ctx, code)
+	if err != nil {
+		slog.Error(" [url_service.go] [DELETE] ", slog.Any("error", err))
+	}
+	return err
+}
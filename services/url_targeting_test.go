@@ -0,0 +1,98 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// staticGeo places every IP address in the same country
+type staticGeo string
+
+func (g staticGeo) Country(ip string) string { return string(g) }
+
+func TestDestination(t *testing.T) {
+	url := &models.Url{
+		URL:       "https://example.com/landing-page",
+		Rules:     []models.RoutingRule{{OS: "ios", URL: "https://apps.apple.com/app/id000000000"}},
+		Countries: map[string]string{"DE": "https://example.de/landing-page"},
+	}
+	iphone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	desktop := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
+	german := NewUrlService(nil, staticGeo("DE"))
+	require.Equal(t, Target{URL: "https://apps.apple.com/app/id000000000"}, german.Destination(url, german.NewVisit(iphone, "192.0.2.1", "")))
+	require.Equal(t, Target{URL: "https://example.de/landing-page"}, german.Destination(url, german.NewVisit(desktop, "192.0.2.1", "")))
+
+	noGeo := NewUrlService(nil, nil)
+	require.Equal(t, Target{URL: "https://example.com/landing-page"}, noGeo.Destination(url, noGeo.NewVisit(desktop, "192.0.2.1", "")))
+}
+
+func TestNewVisitReferrer(t *testing.T) {
+	service := NewUrlService(nil, nil)
+	require.Equal(t, "news.example.com", service.NewVisit("", "192.0.2.1", "https://news.example.com/story?id=1").Referrer)
+	require.Empty(t, service.NewVisit("", "192.0.2.1", "").Referrer)
+
+	// Hosts are cut to the width of clicks.referrer without splitting a character
+	long := service.NewVisit("", "192.0.2.1", "https://"+strings.Repeat("é", 200)+".example/").Referrer
+	require.Len(t, long, 254)
+	require.Equal(t, strings.Repeat("é", 127), long)
+}
+
+func TestDestinationSplit(t *testing.T) {
+	url := &models.Url{
+		URL: "https://example.com/landing-page",
+		Split: &models.Split{Variants: []models.Variant{
+			{Name: "a", URL: "https://example.com/landing-a", Weight: 70},
+			{Name: "b", URL: "https://example.com/landing-b", Weight: 30},
+		}},
+	}
+	service := NewUrlService(nil, nil)
+
+	// Rolls 0-69 pick a, 70-99 pick b
+	for roll, want := range map[int]string{0: "a", 69: "a", 70: "b", 99: "b"} {
+		service.roll = func(n int) int {
+			require.Equal(t, 100, n)
+			return roll
+		}
+		require.Equal(t, want, service.Destination(url, Visit{}).Variant)
+	}
+
+	// The variant of an earlier visit is only kept by sticky splits
+	service.roll = func(n int) int { return 0 }
+	require.Equal(t, "a", service.Destination(url, Visit{Variant: "b"}).Variant)
+	url.Split.Sticky = true
+	require.Equal(t, Target{URL: "https://example.com/landing-b", Variant: "b"}, service.Destination(url, Visit{Variant: "b"}))
+	require.Equal(t, "a", service.Destination(url, Visit{Variant: "removed"}).Variant)
+}
+
+func TestValidateSplit(t *testing.T) {
+	variant := func(name string, weight int) models.Variant {
+		return models.Variant{Name: name, URL: "https://example.com/landing-" + name, Weight: weight}
+	}
+	require.NoError(t, ValidateSplit(nil))
+	require.NoError(t, ValidateSplit(&models.Split{Variants: []models.Variant{variant("a", 70), variant("b", 30)}}))
+
+	for _, split := range []*models.Split{
+		{Variants: []models.Variant{variant("a", 100)}},
+		{Variants: []models.Variant{variant("a", 50), variant("a", 50)}},
+		{Variants: []models.Variant{variant("a", 50), variant("b", 0)}},
+		{Variants: []models.Variant{variant("a", 50), variant("b;c", 50)}},
+	} {
+		require.ErrorIs(t, ValidateSplit(split), utils.ErrInvalidSplit)
+	}
+}
+
+func TestNormalizeCountries(t *testing.T) {
+	countries, err := NormalizeCountries(map[string]string{"de": "https://example.de/landing-page"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"DE": "https://example.de/landing-page"}, countries)
+
+	_, err = NormalizeCountries(map[string]string{"GER": "https://example.de/landing-page"})
+	require.ErrorIs(t, err, utils.ErrInvalidCountryTarget)
+	_, err = NormalizeCountries(map[string]string{"DE": "not a url"})
+	require.ErrorIs(t, err, utils.ErrInvalidCountryTarget)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	neturl "net/url"
+	"strings"
+	"urlshortener/models"
+)
+
+// ApplyUTM adds UTM parameters to destination
+// Fields of explicit replace any parameter of the same name in destination,
+// fields of defaults (a campaign template) are only added when destination doesn't already set them
+// Other parameters keep their order and encoding; the UTM parameters are appended after them
+// Returns destination unchanged if it can't be parsed or no UTM parameter applies
+func ApplyUTM(destination string, explicit models.UTM, defaults models.UTM) string {
+	target, err := neturl.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	existing := target.Query()
+
+	replaced := make(map[string]bool)
+	var added []string
+	for _, param := range explicit.Params() {
+		replaced[param[0]] = true
+		added = append(added, param[0]+"="+neturl.QueryEscape(param[1]))
+	}
+	for _, param := range defaults.Params() {
+		if replaced[param[0]] || existing.Has(param[0]) {
+			continue
+		}
+		added = append(added, param[0]+"="+neturl.QueryEscape(param[1]))
+	}
+	if len(added) == 0 {
+		return destination
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(target.RawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := neturl.QueryUnescape(key); pair == "" || (err == nil && replaced[unescaped]) {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	target.RawQuery = strings.Join(append(pairs, added...), "&")
+	return target.String()
+}
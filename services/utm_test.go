@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"urlshortener/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyUTM(t *testing.T) {
+	explicit := models.UTM{Source: "newsletter", Medium: "email"}
+	template := models.UTM{Source: "twitter", Medium: "social", Campaign: "spring sale"}
+	tests := []struct {
+		name        string
+		destination string
+		explicit    models.UTM
+		defaults    models.UTM
+		want        string
+	}{
+		{"none", "https://example.com/docs?a=1", models.UTM{}, models.UTM{}, "https://example.com/docs?a=1"},
+		{"appended", "https://example.com/docs", explicit, models.UTM{}, "https://example.com/docs?utm_source=newsletter&utm_medium=email"},
+		{"explicit replaces", "https://example.com/docs?utm_source=old&a=1", explicit, models.UTM{}, "https://example.com/docs?a=1&utm_source=newsletter&utm_medium=email"},
+		{"defaults fill in", "https://example.com/docs?utm_medium=print", models.UTM{}, template, "https://example.com/docs?utm_medium=print&utm_source=twitter&utm_campaign=spring+sale"},
+		{"explicit beats defaults", "https://example.com/docs", explicit, template, "https://example.com/docs?utm_source=newsletter&utm_medium=email&utm_campaign=spring+sale"},
+		{"encoding kept", "https://example.com/docs?q=a%20b&x=%26#top", explicit, models.UTM{}, "https://example.com/docs?q=a%20b&x=%26&utm_source=newsletter&utm_medium=email#top"},
+		{"nothing to add", "https://example.com/docs?utm_source=a&utm_medium=b&utm_campaign=c", models.UTM{}, template, "https://example.com/docs?utm_source=a&utm_medium=b&utm_campaign=c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ApplyUTM(tt.destination, tt.explicit, tt.defaults))
+		})
+	}
+}
+
+func TestApplyUTMToEveryDestination(t *testing.T) {
+	url := models.Url{
+		URL:       "https://example.com/landing-page",
+		Rules:     []models.RoutingRule{{OS: "iOS", URL: "https://apps.apple.com/app/id1"}},
+		Countries: map[string]string{"DE": "https://example.de/landing-page?utm_campaign=sommer"},
+		Split:     &models.Split{Variants: []models.Variant{{Name: "a", URL: "https://example.com/a", Weight: 1}}},
+	}
+	applyUTM(&url, LinkOptions{
+		UTM:      &models.UTM{Source: "qr"},
+		Campaign: &models.Campaign{UTM: models.UTM{Campaign: "launch"}},
+	})
+
+	require.Equal(t, "https://example.com/landing-page?utm_source=qr&utm_campaign=launch", url.URL)
+	require.Equal(t, "https://apps.apple.com/app/id1?utm_source=qr&utm_campaign=launch", url.Rules[0].URL)
+	require.Equal(t, "https://example.de/landing-page?utm_campaign=sommer&utm_source=qr", url.Countries["DE"])
+	require.Equal(t, "https://example.com/a?utm_source=qr&utm_campaign=launch", url.Split.Variants[0].URL)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+	"urlshortener/models"
+	"urlshortener/repositories"
+	"urlshortener/utils"
+)
+
+// invitationTokenPrefix makes invitation tokens easy to recognise (e.g., by secret scanners)
+const invitationTokenPrefix = "usi_"
+
+// WorkspaceService provides methods to manage workspaces, their members and invitations
+type WorkspaceService struct {
+	WorkspaceRepo repositories.WorkspaceRepository // Underlying repository for workspace data
+	ClickRepo     repositories.ClickRepository     // Clicks of the workspaces' links
+	invitationTTL time.Duration                    // How long invitations can be accepted for
+}
+
+// NewWorkspaceService creates a new WorkspaceService with the given repositories
+// Invitations expire after invitationTTL
+func NewWorkspaceService(workspaces repositories.WorkspaceRepository, clicks repositories.ClickRepository, invitationTTL time.Duration) *WorkspaceService {
+	return &WorkspaceService{
+		WorkspaceRepo: workspaces,
+		ClickRepo:     clicks,
+		invitationTTL: invitationTTL,
+	}
+}
+
+// hashInvitationToken returns the SHA-256 hash of an invitation token, the only form in which tokens are stored
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateWorkspace creates a workspace on the plan of account, which becomes its owner
+func (w *WorkspaceService) CreateWorkspace(ctx context.Context, account *models.Account, name string) (*models.Membership, error) {
+	now := time.Now()
+	workspace := models.Workspace{
+		Name:      name,
+		Plan:      account.Plan,
+		CreatedAt: now,
+	}
+	id, err := w.WorkspaceRepo.Create(ctx, workspace, models.Member{AccountId: account.Id, Email: account.Email, Role: models.RoleOwner, CreatedAt: now})
+	if err != nil {
+		return nil, err
+	}
+	workspace.Id = id
+	return &models.Membership{Workspace: workspace, Role: models.RoleOwner}, nil
+}
+
+// ListWorkspaces returns the workspaces account is a member of, along with its role in each
+func (w *WorkspaceService) ListWorkspaces(ctx context.Context, account *models.Account) ([]models.Membership, error) {
+	return w.WorkspaceRepo.ListByAccount(ctx, account.Id)
+}
+
+// Membership returns a workspace and the role of account in it; admins act as owners of every workspace
+// Returns utils.ErrUnauthorized for anonymous clients and utils.ErrWorkspaceNotFound for non-members,
+// so the workspaces of other accounts can't be discovered
+func (w *WorkspaceService) Membership(ctx context.Context, account *models.Account, workspaceId int) (*models.Workspace, models.Role, error) {
+	if account == nil {
+		return nil, "", utils.ErrUnauthorized
+	}
+	workspace, err := w.WorkspaceRepo.GetById(ctx, workspaceId)
+	if err != nil {
+		return nil, "", err
+	}
+	if workspace == nil {
+		return nil, "", utils.ErrWorkspaceNotFound
+	}
+	if account.Admin {
+		return workspace, models.RoleOwner, nil
+	}
+	member, err := w.WorkspaceRepo.GetMember(ctx, workspaceId, account.Id)
+	if err != nil {
+		return nil, "", err
+	}
+	if member == nil {
+		return nil, "", utils.ErrWorkspaceNotFound
+	}
+	return workspace, member.Role, nil
+}
+
+// ListMembers returns the members of a workspace
+func (w *WorkspaceService) ListMembers(ctx context.Context, workspaceId int) ([]models.Member, error) {
+	return w.WorkspaceRepo.ListMembers(ctx, workspaceId)
+}
+
+// checkTarget returns the member of a workspace that a member with actorRole is about to change,
+// or utils.ErrForbidden if only owners may change it
+// The repository refuses to demote or remove the last owner, since only it can check that atomically
+func (w *WorkspaceService) checkTarget(ctx context.Context, actorRole models.Role, workspaceId int, accountId int) (*models.Member, error) {
+	member, err := w.WorkspaceRepo.GetMember(ctx, workspaceId, accountId)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, utils.ErrMemberNotFound
+	}
+	if member.Role == models.RoleOwner && actorRole != models.RoleOwner {
+		return nil, utils.ErrForbidden
+	}
+	return member, nil
+}
+
+// SetRole changes the role of a member on behalf of a member with actorRole
+// Only owners may grant the owner role or change the role of owners, and the last owner can't be demoted
+func (w *WorkspaceService) SetRole(ctx context.Context, actorRole models.Role, workspaceId int, accountId int, role models.Role) (*models.Member, error) {
+	if !role.Valid() {
+		return nil, utils.ErrInvalidRole
+	}
+	if role == models.RoleOwner && actorRole != models.RoleOwner {
+		return nil, utils.ErrForbidden
+	}
+	member, err := w.checkTarget(ctx, actorRole, workspaceId, accountId)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.WorkspaceRepo.UpdateRole(ctx, workspaceId, accountId, role); err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember removes an account from a workspace on behalf of a member with actorRole
+// Only owners may remove owners, and the last owner can't be removed
+func (w *WorkspaceService) RemoveMember(ctx context.Context, actorRole models.Role, workspaceId int, accountId int) error {
+	if _, err := w.checkTarget(ctx, actorRole, workspaceId, accountId); err != nil {
+		return err
+	}
+	return w.WorkspaceRepo.RemoveMember(ctx, workspaceId, accountId)
+}
+
+// Invite invites the account with email to a workspace with role, on behalf of inviter whose role is actorRole
+// Returns the invitation and its token, which cannot be retrieved later
+// Only owners may invite owners
+func (w *WorkspaceService) Invite(ctx context.Context, inviter *models.Account, actorRole models.Role, workspaceId int, email string, role models.Role) (*models.Invitation, string, error) {
+	if !role.Valid() {
+		return nil, "", utils.ErrInvalidRole
+	}
+	if role == models.RoleOwner && actorRole != models.RoleOwner {
+		return nil, "", utils.ErrForbidden
+	}
+
+	secret := make([]byte, 24)
+	rand.Read(secret)
+	token := invitationTokenPrefix + hex.EncodeToString(secret)
+	now := time.Now()
+	invitation := models.Invitation{
+		WorkspaceId: workspaceId,
+		Email:       email,
+		Role:        role,
+		TokenHash:   hashInvitationToken(token),
+		InvitedBy:   inviter.Id,
+		ExpiresAt:   now.Add(w.invitationTTL),
+		CreatedAt:   now,
+	}
+	id, err := w.WorkspaceRepo.CreateInvitation(ctx, invitation)
+	if err != nil {
+		return nil, "", err
+	}
+	invitation.Id = id
+	return &invitation, token, nil
+}
+
+// AcceptInvitation adds account to the workspace of the invitation with token, with the role of the invitation
+// Returns utils.ErrInvitationNotFound if the token is unknown, expired, already used or meant for another email
+func (w *WorkspaceService) AcceptInvitation(ctx context.Context, account *models.Account, token string) (*models.Membership, error) {
+	invitation, err := w.WorkspaceRepo.GetInvitationByTokenHash(ctx, hashInvitationToken(token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if invitation == nil || invitation.AcceptedAt != nil || now.After(invitation.ExpiresAt) || !strings.EqualFold(invitation.Email, account.Email) {
+		return nil, utils.ErrInvitationNotFound
+	}
+
+	member := models.Member{WorkspaceId: invitation.WorkspaceId, AccountId: account.Id, Email: account.Email, Role: invitation.Role, CreatedAt: now}
+	if err := w.WorkspaceRepo.AcceptInvitation(ctx, invitation.Id, member, now); err != nil {
+		return nil, err
+	}
+	workspace, err := w.WorkspaceRepo.GetById(ctx, invitation.WorkspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		return nil, utils.ErrWorkspaceNotFound
+	}
+	return &models.Membership{Workspace: *workspace, Role: member.Role}, nil
+}
+
+// GetStats summarizes the clicks of every link of a workspace
+func (w *WorkspaceService) GetStats(ctx context.Context, workspaceId int) (*models.WorkspaceStats, error) {
+	stats, err := w.ClickRepo.WorkspaceStats(ctx, workspaceId)
+	if err != nil {
+		slog.Error(" [workspace_service.go] [GetStats] ", slog.Int("workspace", workspaceId), slog.Any("error", err))
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"urlshortener/models"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWorkspaceRepo stores workspaces, members and invitations in memory
+type memoryWorkspaceRepo struct {
+	mu          sync.Mutex
+	workspaces  []models.Workspace
+	members     []models.Member
+	invitations []models.Invitation
+}
+
+func (m *memoryWorkspaceRepo) Create(ctx context.Context, workspace models.Workspace, owner models.Member) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	workspace.Id = len(m.workspaces) + 1
+	owner.WorkspaceId = workspace.Id
+	m.workspaces = append(m.workspaces, workspace)
+	m.members = append(m.members, owner)
+	return workspace.Id, nil
+}
+
+func (m *memoryWorkspaceRepo) GetById(ctx context.Context, id int) (*models.Workspace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.workspaces) {
+		return nil, nil
+	}
+	workspace := m.workspaces[id-1]
+	return &workspace, nil
+}
+
+func (m *memoryWorkspaceRepo) ListByAccount(ctx context.Context, accountId int) ([]models.Membership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var memberships []models.Membership
+	for _, member := range m.members {
+		if member.AccountId == accountId {
+			memberships = append(memberships, models.Membership{Workspace: m.workspaces[member.WorkspaceId-1], Role: member.Role})
+		}
+	}
+	return memberships, nil
+}
+
+// member returns the index of a member in m.members, or -1
+func (m *memoryWorkspaceRepo) member(workspaceId int, accountId int) int {
+	for i, member := range m.members {
+		if member.WorkspaceId == workspaceId && member.AccountId == accountId {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *memoryWorkspaceRepo) GetMember(ctx context.Context, workspaceId int, accountId int) (*models.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.member(workspaceId, accountId)
+	if i < 0 {
+		return nil, nil
+	}
+	member := m.members[i]
+	return &member, nil
+}
+
+func (m *memoryWorkspaceRepo) ListMembers(ctx context.Context, workspaceId int) ([]models.Member, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var members []models.Member
+	for _, member := range m.members {
+		if member.WorkspaceId == workspaceId {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// lastOwner reports whether the member at index i is the only owner of its workspace
+func (m *memoryWorkspaceRepo) lastOwner(i int) bool {
+	if m.members[i].Role != models.RoleOwner {
+		return false
+	}
+	for j, member := range m.members {
+		if j != i && member.WorkspaceId == m.members[i].WorkspaceId && member.Role == models.RoleOwner {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *memoryWorkspaceRepo) UpdateRole(ctx context.Context, workspaceId int, accountId int, role models.Role) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.member(workspaceId, accountId)
+	if i < 0 {
+		return utils.ErrMemberNotFound
+	}
+	if role != models.RoleOwner && m.lastOwner(i) {
+		return utils.ErrLastOwner
+	}
+	m.members[i].Role = role
+	return nil
+}
+
+func (m *memoryWorkspaceRepo) RemoveMember(ctx context.Context, workspaceId int, accountId int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.member(workspaceId, accountId)
+	if i < 0 {
+		return utils.ErrMemberNotFound
+	}
+	if m.lastOwner(i) {
+		return utils.ErrLastOwner
+	}
+	m.members = append(m.members[:i], m.members[i+1:]...)
+	return nil
+}
+
+func (m *memoryWorkspaceRepo) CreateInvitation(ctx context.Context, invitation models.Invitation) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	invitation.Id = len(m.invitations) + 1
+	m.invitations = append(m.invitations, invitation)
+	return invitation.Id, nil
+}
+
+func (m *memoryWorkspaceRepo) GetInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, invitation := range m.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryWorkspaceRepo) AcceptInvitation(ctx context.Context, invitationId int, member models.Member, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.invitations[invitationId-1].AcceptedAt != nil {
+		return utils.ErrInvitationNotFound
+	}
+	if m.member(member.WorkspaceId, member.AccountId) >= 0 {
+		return utils.ErrMemberAlreadyExists
+	}
+	m.invitations[invitationId-1].AcceptedAt = &at
+	m.members = append(m.members, member)
+	return nil
+}
+
+func TestWorkspaceRoles(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryWorkspaceRepo{}
+	workspaces := NewWorkspaceService(repo, &memoryClickRepo{}, time.Hour)
+
+	owner := &models.Account{Id: 1, Email: "owner@example.com", Plan: "pro"}
+	created, err := workspaces.CreateWorkspace(ctx, owner, "Marketing")
+	require.NoError(t, err)
+	require.Equal(t, models.RoleOwner, created.Role)
+	require.Equal(t, "pro", created.Plan)
+	id := created.Id
+	repo.members = append(repo.members,
+		models.Member{WorkspaceId: id, AccountId: 2, Role: models.RoleAdmin},
+		models.Member{WorkspaceId: id, AccountId: 3, Role: models.RoleViewer},
+	)
+
+	// Non-members can't tell the workspace exists, admins of the service act as owners
+	_, _, err = workspaces.Membership(ctx, &models.Account{Id: 4}, id)
+	require.ErrorIs(t, err, utils.ErrWorkspaceNotFound)
+	_, role, err := workspaces.Membership(ctx, &models.Account{Id: 5, Admin: true}, id)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleOwner, role)
+	_, role, err = workspaces.Membership(ctx, &models.Account{Id: 3}, id)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleViewer, role)
+
+	tests := []struct {
+		name    string
+		actor   models.Role
+		account int
+		role    models.Role
+		err     error
+	}{
+		{"unknown role", models.RoleOwner, 3, "superuser", utils.ErrInvalidRole},
+		{"unknown member", models.RoleOwner, 4, models.RoleEditor, utils.ErrMemberNotFound},
+		{"admin grants owner", models.RoleAdmin, 3, models.RoleOwner, utils.ErrForbidden},
+		{"admin demotes owner", models.RoleAdmin, 1, models.RoleViewer, utils.ErrForbidden},
+		{"last owner demoted", models.RoleOwner, 1, models.RoleAdmin, utils.ErrLastOwner},
+		{"admin promotes viewer", models.RoleAdmin, 3, models.RoleEditor, nil},
+		{"owner grants owner", models.RoleOwner, 2, models.RoleOwner, nil},
+		{"owner demotes owner", models.RoleOwner, 1, models.RoleViewer, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			member, err := workspaces.SetRole(ctx, tt.actor, id, tt.account, tt.role)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.role, member.Role)
+		})
+	}
+
+	// Account 2 is now the only owner
+	require.ErrorIs(t, workspaces.RemoveMember(ctx, models.RoleOwner, id, 2), utils.ErrLastOwner)
+	require.ErrorIs(t, workspaces.RemoveMember(ctx, models.RoleAdmin, id, 2), utils.ErrForbidden)
+	require.NoError(t, workspaces.RemoveMember(ctx, models.RoleAdmin, id, 3))
+	members, err := workspaces.ListMembers(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+}
+
+// TestConcurrentOwnerDemotions checks that two owners stepping down at once can't leave the workspace without an owner
+func TestConcurrentOwnerDemotions(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryWorkspaceRepo{}
+	workspaces := NewWorkspaceService(repo, &memoryClickRepo{}, time.Hour)
+
+	created, err := workspaces.CreateWorkspace(ctx, &models.Account{Id: 1, Plan: "pro"}, "Marketing")
+	require.NoError(t, err)
+	repo.members = append(repo.members, models.Member{WorkspaceId: created.Id, AccountId: 2, Role: models.RoleOwner})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = workspaces.RemoveMember(ctx, models.RoleOwner, created.Id, i+1)
+		}()
+	}
+	wg.Wait()
+
+	require.ElementsMatch(t, []error{nil, utils.ErrLastOwner}, errs)
+	members, err := workspaces.ListMembers(ctx, created.Id)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, models.RoleOwner, members[0].Role)
+}
+
+func TestWorkspaceInvitations(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryWorkspaceRepo{}
+	workspaces := NewWorkspaceService(repo, &memoryClickRepo{}, time.Hour)
+
+	owner := &models.Account{Id: 1, Email: "owner@example.com", Plan: "free"}
+	created, err := workspaces.CreateWorkspace(ctx, owner, "Marketing")
+	require.NoError(t, err)
+
+	_, _, err = workspaces.Invite(ctx, owner, models.RoleAdmin, created.Id, "new@example.com", models.RoleOwner)
+	require.ErrorIs(t, err, utils.ErrForbidden)
+	invitation, token, err := workspaces.Invite(ctx, owner, models.RoleOwner, created.Id, "new@example.com", models.RoleEditor)
+	require.NoError(t, err)
+	require.Regexp(t, `^usi_[0-9a-f]{48}$`, token)
+	require.Equal(t, hashInvitationToken(token), invitation.TokenHash) // Only the hash is stored
+
+	invitee := &models.Account{Id: 2, Email: "New@Example.com"}
+	_, err = workspaces.AcceptInvitation(ctx, &models.Account{Id: 3, Email: "other@example.com"}, token)
+	require.ErrorIs(t, err, utils.ErrInvitationNotFound)
+	_, err = workspaces.AcceptInvitation(ctx, invitee, "usi_unknown")
+	require.ErrorIs(t, err, utils.ErrInvitationNotFound)
+
+	membership, err := workspaces.AcceptInvitation(ctx, invitee, token)
+	require.NoError(t, err)
+	require.Equal(t, created.Id, membership.Id)
+	require.Equal(t, models.RoleEditor, membership.Role)
+
+	// Tokens are single use
+	_, err = workspaces.AcceptInvitation(ctx, invitee, token)
+	require.ErrorIs(t, err, utils.ErrInvitationNotFound)
+
+	// Expired invitations can't be accepted
+	_, token, err = workspaces.Invite(ctx, owner, models.RoleOwner, created.Id, "late@example.com", models.RoleViewer)
+	require.NoError(t, err)
+	repo.invitations[1].ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = workspaces.AcceptInvitation(ctx, &models.Account{Id: 4, Email: "late@example.com"}, token)
+	require.ErrorIs(t, err, utils.ErrInvitationNotFound)
+}
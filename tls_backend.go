@@ -0,0 +1,46 @@
+package main
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"urlshortener/autotls"
+	"urlshortener/repositories"
+	"urlshortener/services"
+	"urlshortener/utils"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newCertManager returns the ACME certificate manager configured by the ACME_* environment variables
+// Certificates are issued for ACME_HOSTS (default the host of SHORT_URL_PREFIX) and every verified custom domain
+func newCertManager(db *sql.DB, domains *services.DomainService) *autocert.Manager {
+	var cache autocert.Cache
+	switch backend := utils.GetEnv("ACME_CACHE", "dir"); backend {
+	case "dir":
+		cache = autocert.DirCache(utils.GetEnv("ACME_CACHE_DIR", "acme-cache"))
+	case "mysql":
+		cache = repositories.NewMysqlCertCache(db) // Shared by every instance
+	default:
+		panic("unknown ACME_CACHE " + backend) // Panic on misconfiguration
+	}
+
+	hosts := utils.GetEnvList("ACME_HOSTS")
+	if len(hosts) == 0 {
+		if prefix, err := url.Parse(os.Getenv("SHORT_URL_PREFIX")); err == nil && prefix.Hostname() != "" {
+			hosts = []string{prefix.Hostname()}
+		}
+	}
+
+	manager, err := autotls.NewManager(autotls.Config{
+		DirectoryURL: utils.GetEnv("ACME_DIRECTORY_URL", autocert.DefaultACMEDirectory),
+		Email:        os.Getenv("ACME_EMAIL"),
+		Hosts:        hosts,
+		RootCAs:      os.Getenv("ACME_CA_ROOTS"),
+		RenewBefore:  utils.GetEnvDuration("ACME_RENEW_BEFORE", 0),
+	}, cache, func(host string) bool { return domains.Match(host) != "" })
+	if err != nil {
+		panic(err) // Panic on misconfiguration
+	}
+	return manager
+}
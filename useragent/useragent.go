@@ -0,0 +1,114 @@
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"sync"
+)
+
+// Values reported when no pattern matches
+const (
+	Other   = "other"   // Unknown OS or browser
+	Desktop = "desktop" // Device class of user agents that don't look like a phone, tablet or bot
+)
+
+// MaxLength is the number of bytes of a User-Agent header that are parsed and remembered.
+// Real user agents are far shorter, so longer headers can't grow the memo or slow down matching.
+const MaxLength = 512
+
+// truncate cuts userAgent to MaxLength bytes.
+func truncate(userAgent string) string {
+	if len(userAgent) > MaxLength {
+		return userAgent[:MaxLength]
+	}
+	return userAgent
+}
+
+//go:embed regexes.json
+var regexesJson []byte
+
+// pattern maps user agents matching regex to name.
+type pattern struct {
+	Name  string `json:"name"`  // Normalized value (e.g., ios, mobile, chrome)
+	Regex string `json:"regex"` // Go regular expression
+	re    *regexp.Regexp
+}
+
+// patterns holds the embedded patterns, tried in order.
+var patterns struct {
+	Device  []pattern `json:"device"`
+	OS      []pattern `json:"os"`
+	Browser []pattern `json:"browser"`
+}
+
+func init() {
+	if err := json.Unmarshal(regexesJson, &patterns); err != nil {
+		panic(err) // Embedded data is broken
+	}
+	for _, list := range [][]pattern{patterns.Device, patterns.OS, patterns.Browser} {
+		for i := range list {
+			list[i].re = regexp.MustCompile(list[i].Regex)
+		}
+	}
+}
+
+// match returns the name of the first pattern matching userAgent, or fallback.
+func match(list []pattern, userAgent string, fallback string) string {
+	for _, p := range list {
+		if p.re.MatchString(userAgent) {
+			return p.Name
+		}
+	}
+	return fallback
+}
+
+// Agent is a parsed user agent.
+type Agent struct {
+	OS      string `json:"os"`      // ios, android, windows, macos, linux, chromeos or other
+	Device  string `json:"device"`  // mobile, tablet, desktop or bot
+	Browser string `json:"browser"` // chrome, safari, firefox, edge, opera, samsung, ie or other
+}
+
+// Parse classifies a User-Agent header.
+func Parse(userAgent string) Agent {
+	userAgent = truncate(userAgent)
+	return Agent{
+		OS:      match(patterns.OS, userAgent, Other),
+		Device:  match(patterns.Device, userAgent, Desktop),
+		Browser: match(patterns.Browser, userAgent, Other),
+	}
+}
+
+// Parser memoizes Parse, since the same few user agents account for most requests.
+// The memo is dropped when it reaches its size, which bounds memory without per-entry bookkeeping.
+type Parser struct {
+	mu      sync.RWMutex     // Guards parsed
+	parsed  map[string]Agent // Parsed user agents
+	maxSize int              // Entries kept before the memo is dropped
+}
+
+// NewParser creates a Parser remembering up to maxSize user agents.
+func NewParser(maxSize int) *Parser {
+	return &Parser{parsed: make(map[string]Agent), maxSize: maxSize}
+}
+
+// Parse classifies a User-Agent header, reusing earlier results.
+func (p *Parser) Parse(userAgent string) Agent {
+	userAgent = truncate(userAgent)
+	p.mu.RLock()
+	agent, ok := p.parsed[userAgent]
+	p.mu.RUnlock()
+	if ok {
+		return agent
+	}
+
+	agent = Parse(userAgent)
+	p.mu.Lock()
+	if len(p.parsed) >= p.maxSize {
+		p.parsed = make(map[string]Agent)
+	}
+	p.parsed[userAgent] = agent
+	p.mu.Unlock()
+	return agent
+}
@@ -0,0 +1,58 @@
+package useragent_test
+
+import (
+	"strings"
+	"testing"
+	"urlshortener/useragent"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      useragent.Agent
+	}{
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			useragent.Agent{OS: "ios", Device: "mobile", Browser: "safari"},
+		},
+		{
+			"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			useragent.Agent{OS: "ios", Device: "tablet", Browser: "chrome"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+			useragent.Agent{OS: "android", Device: "mobile", Browser: "chrome"},
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36",
+			useragent.Agent{OS: "android", Device: "tablet", Browser: "samsung"},
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+			useragent.Agent{OS: "windows", Device: "desktop", Browser: "edge"},
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.4; rv:125.0) Gecko/20100101 Firefox/125.0",
+			useragent.Agent{OS: "macos", Device: "desktop", Browser: "firefox"},
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			useragent.Agent{OS: "other", Device: "bot", Browser: "other"},
+		},
+		{"", useragent.Agent{OS: "other", Device: "desktop", Browser: "other"}},
+		{
+			// Only the first MaxLength bytes are parsed
+			"Mozilla/5.0 (" + strings.Repeat("x", useragent.MaxLength) + "; Windows NT 10.0) Chrome/124.0.0.0",
+			useragent.Agent{OS: "other", Device: "desktop", Browser: "other"},
+		},
+	}
+
+	parser := useragent.NewParser(2)
+	for _, tt := range tests {
+		require.Equal(t, tt.want, useragent.Parse(tt.userAgent), tt.userAgent)
+		require.Equal(t, tt.want, parser.Parse(tt.userAgent), tt.userAgent)
+		require.Equal(t, tt.want, parser.Parse(tt.userAgent), tt.userAgent) // Memoized
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+)
+
+// FieldError describes a single field of a request that failed validation
+type FieldError struct {
+	Field   string `json:"field"`   // JSON path of the offending field (e.g., expire_in)
+	Message string `json:"message"` // Human-readable reason the field was rejected
+}
+
+// AppError is an error that carries everything needed to render an API error response.
+// Code is a stable, machine-readable string (e.g., link_expired) that clients can switch on,
+// while Message is meant for humans and may change.
+type AppError struct {
+	Status    int          // HTTP status code
+	Code      string       // Stable machine-readable error code
+	Message   string       // Human-readable error message
+	RequestId string       // Id of the request that failed (set by the error middleware)
+	Fields    []FieldError // Field-level details for validation errors
+	Err       error        // Underlying error, if any
+}
+
+// Error returns the human-readable message of the error
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error so errors.Is and errors.As keep working
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// sentinelError maps a sentinel error to its API representation
+type sentinelError struct {
+	sentinel error    // Sentinel error from errors.go
+	appErr   AppError // Status, code and message rendered for it
+}
+
+// appErrors maps every sentinel error to its API representation
+// It is a slice rather than a map so an error wrapping several sentinels always maps to the first one listed
+var appErrors = []sentinelError{
+	{ErrUrlNotFound, AppError{Status: http.StatusNotFound, Code: "link_not_found", Message: "URL not found"}},
+	{ErrUrlAlreadyExists, AppError{Status: http.StatusConflict, Code: "link_already_exists", Message: "URL already exists"}},
+	{ErrInvalidUrl, AppError{Status: http.StatusBadRequest, Code: "invalid_url", Message: "Invalid URL format"}},
+	{ErrUrlTooShort, AppError{Status: http.StatusBadRequest, Code: "url_too_short", Message: "URL must be longer than 25 characters"}},
+	{ErrShortCodeExpired, AppError{Status: http.StatusGone, Code: "link_expired", Message: "URL has expired"}},
+	{ErrShortCodeCollision, AppError{Status: http.StatusConflict, Code: "short_code_collision", Message: "Short code collision, please retry"}},
+	{ErrDatabaseConnection, AppError{Status: http.StatusServiceUnavailable, Code: "service_unavailable", Message: "Service temporarily unavailable"}},
+	{ErrDatabaseQuery, AppError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "Internal server error"}},
+	{ErrDatabaseInsert, AppError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "Internal server error"}},
+	{ErrDatabaseUpdate, AppError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "Internal server error"}},
+	{ErrDatabaseDelete, AppError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "Internal server error"}},
+	{ErrShortCodeRequired, AppError{Status: http.StatusBadRequest, Code: "short_code_required", Message: "Short URL code is required"}},
+	{ErrValidation, AppError{Status: http.StatusUnprocessableEntity, Code: "validation_error", Message: "Validation error"}},
+	{ErrRateLimitExceeded, AppError{Status: http.StatusTooManyRequests, Code: "rate_limit_exceeded", Message: "Rate limit exceeded"}},
+	{ErrCacheUnavailable, AppError{Status: http.StatusServiceUnavailable, Code: "service_unavailable", Message: "Service temporarily unavailable"}},
+	{ErrUnauthorized, AppError{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "Invalid API key"}},
+	{ErrForbidden, AppError{Status: http.StatusForbidden, Code: "forbidden", Message: "Not allowed to perform this action"}},
+	{ErrQuotaExceeded, AppError{Status: http.StatusTooManyRequests, Code: "quota_exceeded", Message: "Link quota exceeded"}},
+	{ErrAccountNotFound, AppError{Status: http.StatusNotFound, Code: "account_not_found", Message: "Account not found"}},
+	{ErrAccountAlreadyExists, AppError{Status: http.StatusConflict, Code: "account_already_exists", Message: "An account with this email already exists"}},
+	{ErrUnknownPlan, AppError{Status: http.StatusBadRequest, Code: "unknown_plan", Message: "Unknown plan"}},
+	{ErrClientBanned, AppError{Status: http.StatusTooManyRequests, Code: "client_banned", Message: "Too many requests for unknown links, try again later"}},
+	{ErrInvalidRoutingRule, AppError{Status: http.StatusBadRequest, Code: "invalid_routing_rule", Message: "Routing rules need a valid URL and at least one of os, device or browser"}},
+	{ErrInvalidCountryTarget, AppError{Status: http.StatusBadRequest, Code: "invalid_country_target", Message: "Country destinations need two-letter country codes and valid URLs"}},
+	{ErrInvalidSplit, AppError{Status: http.StatusBadRequest, Code: "invalid_split", Message: "Splits need 2 to 10 uniquely named variants with weights from 1 to 10000 and valid URLs"}},
+	{ErrCampaignNotFound, AppError{Status: http.StatusNotFound, Code: "campaign_not_found", Message: "Campaign not found"}},
+	{ErrCampaignAlreadyExists, AppError{Status: http.StatusConflict, Code: "campaign_already_exists", Message: "A campaign with this name already exists"}},
+	{ErrInvalidDomain, AppError{Status: http.StatusBadRequest, Code: "invalid_domain", Message: "Domains must be valid host names with at least two labels"}},
+	{ErrDomainNotFound, AppError{Status: http.StatusNotFound, Code: "domain_not_found", Message: "Domain not found"}},
+	{ErrDomainAlreadyExists, AppError{Status: http.StatusConflict, Code: "domain_already_exists", Message: "This domain has already been added"}},
+	{ErrDomainNotVerified, AppError{Status: http.StatusConflict, Code: "domain_not_verified", Message: "Domain ownership has not been verified, publish the verification TXT record and retry"}},
+	{ErrInvalidDeepLink, AppError{Status: http.StatusBadRequest, Code: "invalid_deep_link", Message: "Deep links need an app URI for ios or android with a scheme other than javascript, data, vbscript or file"}},
+	{ErrWorkspaceNotFound, AppError{Status: http.StatusNotFound, Code: "workspace_not_found", Message: "Workspace not found"}},
+	{ErrInvalidRole, AppError{Status: http.StatusBadRequest, Code: "invalid_role", Message: "Roles must be owner, admin, editor or viewer"}},
+	{ErrMemberNotFound, AppError{Status: http.StatusNotFound, Code: "member_not_found", Message: "Member not found"}},
+	{ErrMemberAlreadyExists, AppError{Status: http.StatusConflict, Code: "member_already_exists", Message: "This account is already a member of the workspace"}},
+	{ErrLastOwner, AppError{Status: http.StatusConflict, Code: "last_owner", Message: "A workspace must keep at least one owner"}},
+	{ErrInvitationNotFound, AppError{Status: http.StatusNotFound, Code: "invitation_not_found", Message: "Invitation not found, expired or already accepted"}},
+	{ErrInvalidTag, AppError{Status: http.StatusBadRequest, Code: "invalid_tag", Message: "Links can have up to 20 tags of 1 to 32 letters, digits, spaces, dots, dashes or underscores"}},
+}
+
+// ToAppError converts any error to an AppError.
+// AppErrors are returned as is, sentinel errors are mapped through appErrors
+// and anything else becomes a generic internal_error.
+func ToAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	for _, mapped := range appErrors {
+		if errors.Is(err, mapped.sentinel) {
+			appErr := mapped.appErr
+			appErr.Err = err
+			return &appErr
+		}
+	}
+
+	return &AppError{
+		Status:  http.StatusInternalServerError,
+		Code:    "internal_error",
+		Message: "Internal server error",
+		Err:     err,
+	}
+}
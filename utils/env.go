@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv reads the environment variable key.
+// Returns def if the variable is unset.
+func GetEnv(key string, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// GetEnvList reads a comma-separated list from the environment variable key, ignoring blank items.
+// Returns nil if the variable is unset.
+func GetEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetEnvDuration reads a duration (e.g., 200ms, 5s) from the environment variable key.
+// Returns def if the variable is unset or cannot be parsed.
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Error(" [env.go] [PARSE DURATION] ", slog.String("key", key), slog.Any("error", err))
+		return def
+	}
+	return duration
+}
+
+// GetEnvInt reads an integer from the environment variable key.
+// Returns def if the variable is unset or cannot be parsed.
+func GetEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Error(" [env.go] [PARSE INT] ", slog.String("key", key), slog.Any("error", err))
+		return def
+	}
+	return number
+}
+
+// GetEnvBool reads a boolean (true, false, 1, 0, ...) from the environment variable key.
+// Returns def if the variable is unset or cannot be parsed.
+func GetEnvBool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Error(" [env.go] [PARSE BOOL] ", slog.String("key", key), slog.Any("error", err))
+		return def
+	}
+	return flag
+}
+
+// GetEnvFloat reads a floating point number from the environment variable key.
+// Returns def if the variable is unset or cannot be parsed.
+func GetEnvFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Error(" [env.go] [PARSE FLOAT] ", slog.String("key", key), slog.Any("error", err))
+		return def
+	}
+	return number
+}
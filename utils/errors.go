@@ -3,14 +3,43 @@ package utils
 import "errors"
 
 var (
-	ErrUrlNotFound         = errors.New("URL not found")
-	ErrUrlAlreadyExists    = errors.New("URL already exists")
-	ErrInvalidUrl          = errors.New("invalid URL format")
-	ErrShortCodeExpired    = errors.New("short code has expired")
-	ErrShortCodeCollision  = errors.New("short code collision detected")
-	ErrDatabaseConnection  = errors.New("database connection error")
-	ErrDatabaseQuery       = errors.New("database query error")
-	ErrDatabaseInsert      = errors.New("database insert error")
-	ErrDatabaseUpdate      = errors.New("database update error")
-	ErrDatabaseDelete      = errors.New("database delete error")
+	ErrUrlNotFound           = errors.New("URL not found")
+	ErrUrlAlreadyExists      = errors.New("URL already exists")
+	ErrInvalidUrl            = errors.New("invalid URL format")
+	ErrUrlTooShort           = errors.New("URL must be longer than 25 characters")
+	ErrShortCodeExpired      = errors.New("short code has expired")
+	ErrShortCodeCollision    = errors.New("short code collision detected")
+	ErrDatabaseConnection    = errors.New("database connection error")
+	ErrDatabaseQuery         = errors.New("database query error")
+	ErrDatabaseInsert        = errors.New("database insert error")
+	ErrDatabaseUpdate        = errors.New("database update error")
+	ErrDatabaseDelete        = errors.New("database delete error")
+	ErrShortCodeRequired     = errors.New("short URL code is required")
+	ErrValidation            = errors.New("validation error")
+	ErrRateLimitExceeded     = errors.New("rate limit exceeded")
+	ErrCacheUnavailable      = errors.New("cache unavailable")
+	ErrUnauthorized          = errors.New("invalid API key")
+	ErrForbidden             = errors.New("forbidden")
+	ErrQuotaExceeded         = errors.New("quota exceeded")
+	ErrAccountNotFound       = errors.New("account not found")
+	ErrAccountAlreadyExists  = errors.New("account already exists")
+	ErrUnknownPlan           = errors.New("unknown plan")
+	ErrClientBanned          = errors.New("client temporarily banned")
+	ErrInvalidRoutingRule    = errors.New("invalid routing rule")
+	ErrInvalidCountryTarget  = errors.New("invalid country destination")
+	ErrInvalidSplit          = errors.New("invalid split")
+	ErrCampaignNotFound      = errors.New("campaign not found")
+	ErrCampaignAlreadyExists = errors.New("campaign already exists")
+	ErrInvalidDeepLink       = errors.New("invalid deep link")
+	ErrInvalidDomain         = errors.New("invalid domain")
+	ErrDomainNotFound        = errors.New("domain not found")
+	ErrDomainAlreadyExists   = errors.New("domain already exists")
+	ErrDomainNotVerified     = errors.New("domain not verified")
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrMemberNotFound        = errors.New("member not found")
+	ErrMemberAlreadyExists   = errors.New("member already exists")
+	ErrLastOwner             = errors.New("workspace needs an owner")
+	ErrInvitationNotFound    = errors.New("invitation not found")
+	ErrInvalidTag            = errors.New("invalid tag")
 )
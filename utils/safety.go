@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// shortenerHosts are URL shorteners whose links hide their real destination
+var shortenerHosts = map[string]bool{
+	"bit.ly": true, "tinyurl.com": true, "t.co": true, "goo.gl": true, "ow.ly": true,
+	"is.gd": true, "buff.ly": true, "rebrand.ly": true, "cutt.ly": true, "shorturl.at": true,
+}
+
+// downloadExtensions are file types that install or run software when opened
+var downloadExtensions = map[string]bool{
+	".exe": true, ".msi": true, ".scr": true, ".bat": true, ".cmd": true, ".ps1": true,
+	".apk": true, ".dmg": true, ".pkg": true, ".jar": true, ".vbs": true,
+}
+
+// SafetyWarnings returns human-readable warnings about a destination URL,
+// shown on the preview page so users can decide whether to follow a link
+// Returns nil if nothing looks suspicious
+func SafetyWarnings(rawUrl string) []string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return []string{"The destination is not a valid URL."}
+	}
+
+	var warnings []string
+	host := u.Hostname()
+	if u.Scheme != "https" {
+		warnings = append(warnings, "The destination does not use HTTPS, so the connection is not encrypted.")
+	}
+	if u.User != nil {
+		warnings = append(warnings, "The destination contains a user name, which is a common trick to disguise the real site.")
+	}
+	if net.ParseIP(host) != nil {
+		warnings = append(warnings, "The destination is a raw IP address rather than a domain name.")
+	}
+	for _, label := range strings.Split(host, ".") {
+		if strings.HasPrefix(strings.ToLower(label), "xn--") {
+			warnings = append(warnings, "The domain uses international characters that can imitate another site.")
+			break
+		}
+	}
+	if shortenerHosts[strings.TrimPrefix(strings.ToLower(host), "www.")] {
+		warnings = append(warnings, "The destination is another short link, so its final destination is unknown.")
+	}
+	if downloadExtensions[strings.ToLower(path.Ext(u.Path))] {
+		warnings = append(warnings, "The destination downloads a program or installer.")
+	}
+	return warnings
+}
@@ -0,0 +1,45 @@
+package utils_test
+
+import (
+	"testing"
+	"urlshortener/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafetyWarnings(t *testing.T) {
+	const (
+		noHttps    = "The destination does not use HTTPS, so the connection is not encrypted."
+		userName   = "The destination contains a user name, which is a common trick to disguise the real site."
+		ipAddress  = "The destination is a raw IP address rather than a domain name."
+		punycode   = "The domain uses international characters that can imitate another site."
+		shortLink  = "The destination is another short link, so its final destination is unknown."
+		download   = "The destination downloads a program or installer."
+		invalidUrl = "The destination is not a valid URL."
+	)
+	tests := []struct {
+		name     string
+		url      string
+		warnings []string
+	}{
+		{"safe", "https://example.com/landing-page?ref=mail", nil},
+		{"plain http", "http://example.com/landing-page", []string{noHttps}},
+		{"user name", "https://paypal.com@evil.example/login", []string{userName}},
+		{"ipv4 address", "https://192.0.2.10/login", []string{ipAddress}},
+		{"ipv6 address", "https://[2001:db8::1]:8443/login", []string{ipAddress}},
+		{"punycode", "https://xn--pypal-4ve.com/login", []string{punycode}},
+		{"punycode subdomain", "https://login.XN--80ak6aa92e.com/", []string{punycode}},
+		{"other shortener", "https://bit.ly/3abcDEF", []string{shortLink}},
+		{"other shortener with www", "https://www.TinyURL.com/abc", []string{shortLink}},
+		{"installer", "https://example.com/files/setup.EXE", []string{download}},
+		{"android package", "https://example.com/app.apk?v=2", []string{download}},
+		{"document", "https://example.com/report.pdf", nil},
+		{"invalid", "https://[::1/path", []string{invalidUrl}},
+		{"several", "http://user@203.0.113.5/payload.ps1", []string{noHttps, userName, ipAddress, download}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.warnings, utils.SafetyWarnings(tt.url))
+		})
+	}
+}
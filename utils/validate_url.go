@@ -0,0 +1,20 @@
+package utils
+
+import "net/url"
+
+// minUrlLength is the length a URL must exceed to be worth shortening
+const minUrlLength = 25
+
+// ValidateUrl checks that rawUrl is an absolute URL with a scheme and host
+// and that it is longer than minUrlLength characters.
+// Returns ErrInvalidUrl or ErrUrlTooShort if the URL is rejected.
+func ValidateUrl(rawUrl string) error {
+	u, err := url.ParseRequestURI(rawUrl)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ErrInvalidUrl
+	}
+	if len(rawUrl) <= minUrlLength {
+		return ErrUrlTooShort
+	}
+	return nil
+}